@@ -0,0 +1,95 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+
+	"github.com/lloydlobo/go-headcount/handlers"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// routeMux is a ServeMux remembering its patterns, for /debug/routes.
+type routeMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func newRouteMux() *routeMux { return &routeMux{ServeMux: http.NewServeMux()} }
+
+func (m *routeMux) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, handler)
+}
+
+func (m *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// adminRoutes returns the routes of the admin listener, see
+// internal.Config.AdminEnabled. They are never registered on the public
+// router. With internal.Config.WithProfiling:
+//
+//	/debug/pprof/          profiles of net/http/pprof
+//	/debug/vars            expvar variables, see publishVars
+//	/debug/routes          the patterns of router, in registration order
+//
+// With internal.Config.AdminBackups:
+//
+//	/admin/backup          the roster as a snapshot, to download
+//	/admin/backups/{name}  a saved snapshot, to download
+//	/admin/restore         restores the roster from a posted snapshot
+func adminRoutes(router *routeMux, h *handlers.DefaultHandler) *http.ServeMux {
+	mux := http.NewServeMux()
+	if h.Config.AdminBackups {
+		mux.Handle("GET /admin/backup", h.HandleErrors(h.HandleDownloadBackup))
+		mux.Handle("GET /admin/backups/{name}", h.HandleErrors(h.HandleDownloadSnapshot))
+		mux.Handle("POST /admin/restore", h.HandleErrors(h.HandleRestoreSnapshot))
+	}
+	if !h.Config.WithProfiling {
+		return mux
+	}
+
+	publishVars(h)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, pattern := range router.patterns {
+			fmt.Fprintln(w, pattern)
+		}
+	})
+	mux.Handle("GET /{$}", http.RedirectHandler("/debug/pprof/", http.StatusFound))
+	return mux
+}
+
+var publishOnce sync.Once
+
+// publishVars publishes the counters of h as expvar variables:
+//
+//	build     BuildTag and BuildID
+//	contacts  the contacts by status, and their total
+//	sessions  the browser sessions started
+//
+// expvar variables are global, so only the first handler is published.
+func publishVars(h *handlers.DefaultHandler) {
+	publishOnce.Do(func() {
+		expvar.Publish("build", expvar.Func(func() any {
+			return map[string]string{"tag": BuildTag, "id": BuildID}
+		}))
+		expvar.Publish("contacts", expvar.Func(func() any {
+			counts := map[string]int{"total": h.ContactService.Count()}
+			for _, s := range models.Statuses {
+				counts[s.String()] = h.ContactService.CountByStatus(s)
+			}
+			return counts
+		}))
+		expvar.Publish("sessions", expvar.Func(func() any { return h.Sessions() }))
+	})
+}
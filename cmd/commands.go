@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/services"
+)
+
+var errNoDataFile error = errors.New("no data file: set -data or DATA_FILE")
+
+// command is a subcommand of the CLI, run with the arguments following its
+// name.
+type command struct {
+	usage string
+	run   func(name string, args []string) error
+}
+
+// commands are the subcommands of the CLI, see printUsage. Every command
+// takes the flags of internal.Config, and all but serve and version operate
+// on the roster saved at -data.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":   {"[flags] [-restore snapshot]\n\tRuns the web server, the default command.", runServe},
+		"import":  {"[flags] [-format csv|json|vcard] file\n\tAppends the contacts of a CSV, JSON or vCard file to the roster.", runImport},
+		"export":  {"[flags] [-format csv|json|vcard|xlsx] [-o file]\n\tWrites the roster to file, or stdout.", runExport},
+		"seed":    {"[flags] [-count n] [-seed s]\n\tAdds n made-up contacts, the same for the same seed: seeding\n\tagain with a seed updates its contacts in place.", runSeed},
+		"reset":   {"[flags] -yes\n\tDeletes every contact of the roster.", runReset},
+		"version": {"\n\tPrints the build version.", runVersion},
+		"help":    {"\n\tPrints this help.", runHelp},
+	}
+}
+
+// printUsage lists the commands to stderr.
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: headcount [command] [flags]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nRun headcount [command] -h to list the flags of a command.\n")
+}
+
+func runServe(name string, args []string) error {
+	var restore string
+	cfg, flags, err := internal.LoadConfig(name, args, os.LookupEnv, func(fs *flag.FlagSet) {
+		fs.StringVar(&restore, "restore", "", "restore the roster from a snapshot `file` at startup")
+	})
+	if err != nil || printInfo(name, cfg, flags) {
+		return err
+	}
+	return serve(cfg, restore)
+}
+
+func runImport(name string, args []string) error {
+	var format string
+	cfg, flags, err := internal.LoadConfig(name, args, os.LookupEnv, func(fs *flag.FlagSet) {
+		fs.StringVar(&format, "format", "", "`format` of the file, csv, json or vcard, by default its extension")
+	})
+	if err != nil || printInfo(name, cfg, flags) {
+		return err
+	}
+	if len(flags.Args) != 1 {
+		return fmt.Errorf("usage: %s %s", name, commands[name].usage)
+	}
+
+	path := flags.Args[0]
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	cs, err := openContactService(cfg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var contacts models.Contacts
+	switch format {
+	case "csv":
+		contacts, err = services.ReadCSV(f, cs.Fields(), cfg.PhoneRegion)
+	case "json":
+		contacts, err = services.ReadJSON(f, cs.Fields(), cfg.PhoneRegion)
+	case "vcard", "vcf":
+		contacts, err = services.ReadVCard(f, cs.Fields(), cfg.PhoneRegion)
+	default:
+		return fmt.Errorf("unknown import format %q: use csv, json or vcard", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	added, updated := cs.Import(contacts)
+	if err := cs.Save(cfg.DataFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d contacts into %s, updated %d\n", added, cfg.DataFile, updated)
+	return nil
+}
+
+func runExport(name string, args []string) error {
+	var format, out string
+	cfg, flags, err := internal.LoadConfig(name, args, os.LookupEnv, func(fs *flag.FlagSet) {
+		fs.StringVar(&format, "format", "csv", "`format` to export, csv, json, vcard or xlsx")
+		fs.StringVar(&out, "o", "", "output `file`, by default stdout")
+	})
+	if err != nil || printInfo(name, cfg, flags) {
+		return err
+	}
+
+	var write func(w io.Writer, cs *services.ContactService) error
+	switch format {
+	case "csv":
+		write = func(w io.Writer, cs *services.ContactService) error {
+			return services.WriteCSV(w, cs.Contacts, cs.Fields())
+		}
+	case "json":
+		write = func(w io.Writer, cs *services.ContactService) error { return services.WriteJSON(w, cs.Contacts) }
+	case "vcard":
+		write = func(w io.Writer, cs *services.ContactService) error { return services.WriteVCard(w, cs.Contacts) }
+	case "xlsx":
+		write = func(w io.Writer, cs *services.ContactService) error {
+			return services.WriteXLSX(w, cs.Contacts, cs.Fields())
+		}
+	default:
+		return fmt.Errorf("unknown export format %q: use csv, json, vcard or xlsx", format)
+	}
+
+	cs, err := openContactService(cfg)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return write(os.Stdout, cs)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := write(f, cs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runSeed(name string, args []string) error {
+	var (
+		count int
+		seed  uint64
+	)
+	cfg, flags, err := internal.LoadConfig(name, args, os.LookupEnv, func(fs *flag.FlagSet) {
+		fs.IntVar(&count, "count", 50, "`number` of contacts to add")
+		fs.Uint64Var(&seed, "seed", 1, "`seed` of the made-up contacts")
+	})
+	if err != nil || printInfo(name, cfg, flags) {
+		return err
+	}
+	if count < 0 {
+		return fmt.Errorf("invalid count %d: must not be negative", count)
+	}
+
+	cs, err := openContactService(cfg)
+	if err != nil {
+		return err
+	}
+	added, updated := cs.Import(services.FakeContacts(count, seed))
+	if err := cs.Save(cfg.DataFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "seeded %d contacts into %s, updated %d\n", added, cfg.DataFile, updated)
+	return nil
+}
+
+func runReset(name string, args []string) error {
+	var yes bool
+	cfg, flags, err := internal.LoadConfig(name, args, os.LookupEnv, func(fs *flag.FlagSet) {
+		fs.BoolVar(&yes, "yes", false, "confirm deleting every contact")
+	})
+	if err != nil || printInfo(name, cfg, flags) {
+		return err
+	}
+	if !yes {
+		return errors.New("reset deletes every contact: confirm with -yes")
+	}
+
+	cs, err := openContactService(cfg)
+	if err != nil {
+		return err
+	}
+	n := cs.Count()
+	cs.ResetContacts()
+	if err := cs.Save(cfg.DataFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "deleted %d contacts from %s\n", n, cfg.DataFile)
+	return nil
+}
+
+// printInfo prints the version for -v or the config for -print-config,
+// reporting whether it did and the command must stop there. Every command
+// takes both flags, see internal.LoadConfig.
+func printInfo(name string, cfg internal.Config, flags internal.ConfigFlags) bool {
+	switch {
+	case flags.Version:
+		runVersion(name, nil)
+	case flags.PrintConfig:
+		fmt.Println(cfg)
+	default:
+		return false
+	}
+	return true
+}
+
+func runVersion(name string, args []string) error {
+	fmt.Printf("headcount %s (build %s)\n", BuildTag, BuildID)
+	return nil
+}
+
+func runHelp(name string, args []string) error {
+	printUsage()
+	return nil
+}
+
+// openContactService opens the roster saved at cfg.DataFile, empty if the
+// file does not exist yet, with the custom fields of cfg.CustomFieldsFile
+// if set.
+func openContactService(cfg internal.Config) (*services.ContactService, error) {
+	if cfg.DataFile == "" {
+		return nil, errNoDataFile
+	}
+
+	cs := services.NewContactService(cfg)
+	if err := cs.Load(cfg.DataFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := loadCustomFields(cs, cfg); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// loadCustomFields replaces the custom fields of cs with the ones of
+// cfg.CustomFieldsFile, if set.
+func loadCustomFields(cs *services.ContactService, cfg internal.Config) error {
+	if cfg.CustomFieldsFile == "" {
+		return nil
+	}
+	fields, err := services.LoadFieldDefinitions(cfg.CustomFieldsFile)
+	if err != nil {
+		return fmt.Errorf("error loading custom fields: %w", err)
+	}
+	cs.SetFields(fields)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lloydlobo/go-headcount/internal"
+)
+
+func TestCommandsPrintInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.json")
+	if err := runSeed("seed", []string{"-data", path, "-count", "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"reset", []string{"-yes", "-print-config"}},
+		{"reset", []string{"-yes", "-v"}},
+		{"seed", []string{"-print-config"}},
+		{"import", []string{"-v", "missing.csv"}},
+		{"export", []string{"-print-config", "-o", filepath.Join(t.TempDir(), "out.csv")}},
+	}
+	for _, tt := range tests {
+		args := append([]string{"-data", path}, tt.args...)
+		if err := commands[tt.name].run(tt.name, args); err != nil {
+			t.Errorf("%s %v: %v", tt.name, tt.args, err)
+		}
+
+		cs, err := openContactService(internal.Config{DataFile: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cs.Count(); got != 3 {
+			t.Errorf("%s %v: got %d contacts, want 3", tt.name, tt.args, got)
+		}
+	}
+}
@@ -2,12 +2,84 @@
 //
 // # Usage
 //
-//	go run main.go [flags] [path ...]
-//
-// The flags are:
+//	go run ./cmd [command] [flags]
+//
+// The commands are:
+//
+//	serve [-restore file]
+//		Runs the web server, the default command.
+//	import [-format csv|json|vcard] file
+//		Appends the contacts of a CSV, JSON or vCard file to the roster.
+//	export [-format csv|json|vcard|xlsx] [-o file]
+//		Writes the roster to file, or stdout.
+//	seed [-count n] [-seed s]
+//		Adds n made-up contacts, the same for the same seed: seeding
+//		again with a seed updates its contacts in place.
+//	reset -yes
+//		Deletes every contact of the roster.
+//	version
+//		Prints the build version, see BuildID.
+//
+// All commands but serve operate on the roster saved at -data, or
+// DATA_FILE. Without one, serve seeds a roster from -api-url that each new
+// session resets.
+//
+// The flags common to all commands are:
 //
 //	-v
-//		Prints build version. (@unimplemented)
+//		Prints build version.
+//	-data file
+//		Loads the roster from a JSON file, saved back on shutdown.
+//	-snapshot-dir dir
+//		Snapshots the roster to dir every -snapshot-interval, 5m by
+//		default, if changed, keeping the newest -snapshot-keep, and on
+//		POST /admin/backup. At startup the newest snapshot is restored if
+//		newer than -data, e.g. after a crash.
+//	-restore file
+//		Restores the roster from a snapshot file at startup, with serve.
+//	-event-name name
+//		Heads the attendance report of /stats/report.pdf.
+//	-tls-cert file -tls-key file
+//		Serves HTTPS, with HTTP/2, instead of plain HTTP.
+//	-tls-self-signed
+//		Generates a self-signed certificate at -tls-cert and -tls-key if
+//		missing, for venues without a domain name.
+//	-redirect-port port
+//		Redirects plain HTTP on port to HTTPS.
+//	-profiling
+//		Serves net/http/pprof, expvar at /debug/vars and the registered
+//		routes at /debug/routes on -admin-addr, localhost:6060 by default,
+//		never on the public port.
+//	-admin-backups
+//		Serves downloads and restores of roster snapshots at /admin/backup,
+//		/admin/backups/{name} and /admin/restore on -admin-addr, never on
+//		the public port.
+//	-chaos
+//		Injects latency, 500 and 503 errors, and dropped connections per
+//		route, editable at /debug/chaos. Rules can also be set with
+//		chaosRules in the config file. For development only.
+//	-debug-sleep
+//		Delays every response by -debug-sleep-secs, enabling -chaos.
+//	-read-timeout, -write-timeout, -idle-timeout duration
+//		Bound slow clients, e.g. -write-timeout 2m for large exports.
+//	-drain-delay duration
+//		Reports GET /readyz unavailable for duration before shutting down,
+//		for load balancers to stop routing to the server.
+//	-shutdown-timeout duration
+//		Gives in-flight requests duration to finish on shutdown.
+//	-rate-limit-create, -rate-limit-writes, -rate-limit-bulk n
+//		Allow each client IP n mutations per minute, 0 for no limit.
+//		Desks behind one NAT share an IP and a budget: raise the limits,
+//		e.g. -rate-limit-create 200, when several check in at once.
+//	-print-config
+//		Prints the loaded config as JSON, secrets redacted.
+//	-config file
+//		Reads settings from a JSON config file, also set by CONFIG_FILE.
+//
+// Every setting of internal.Config also has a flag, e.g. -port or
+// -phone-region, and an environment variable, e.g. PORT or PHONE_REGION.
+// Flags override environment variables, which override the config file,
+// which overrides the defaults. Run with -h to list them all.
 //
 // # Build and Deploy
 //
@@ -18,7 +90,7 @@
 //
 // Build Command:
 //
-//	$ go build -tags netgo -ldflags '-s -w' -o app
+//	$ go build -tags netgo -ldflags "-s -w -X main.BuildID=$(git rev-parse --short HEAD)" -o app ./cmd
 //
 // # Develop
 //
@@ -65,6 +137,9 @@
 //
 // Why: Seems like `air` dev tool error.
 //
+// Solved: mutation routes are rate limited per client IP, see internal.RateLimiter
+// and -rate-limit-create.
+//
 // 2024/02/07 17:17:13
 //
 //	http: superfluous response.WriteHeader call from github.com/lloydlobo/go-headcount/handlers.(*DefaultHandler).ContactPartialsHandler (default.go:130)
@@ -2,18 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/lloydlobo/go-headcount/handlers"
 	"github.com/lloydlobo/go-headcount/internal"
 	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/static"
 )
 
 type (
@@ -24,101 +32,438 @@ const (
 	loggerKey loggerKeyKind = "logger"
 )
 
+// cspReportPath collects Content-Security-Policy violation reports.
+const cspReportPath = "/csp-report"
+
+// BuildID identifies the build, set at link time, e.g.
+//
+//	go build -ldflags "-X main.BuildID=$(git rev-parse --short HEAD)" ./cmd
 var (
-	BuildID  = uuid.New().String()
+	BuildID  = "dev"
 	BuildTag = "v0.0.2"
 )
 
 func main() {
-	port := internal.LookupEnv("PORT", "1234")
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
-	defer stop()
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	if err := cmd.run(name, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the web server until interrupted. With a data file, the roster
+// is loaded from it and saved back on shutdown. With a snapshot directory,
+// the roster is snapshot periodically and on shutdown, and restored at
+// startup from the snapshot at restore, or else from the newest snapshot if
+// newer than the data file, e.g. after a crash.
+//
+// On SIGINT or SIGTERM the server drains: /readyz reports unavailable for
+// cfg.DrainDelay, then in-flight requests get cfg.ShutdownTimeout to
+// finish, background goroutines stop and the roster is saved. A second
+// signal exits at once.
+func serve(cfg internal.Config, restore string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	logger := log.New(os.Stderr, "HTTP ", log.LstdFlags)
 	ctx = context.WithValue(ctx, loggerKey, logger)
 
-	cs := services.NewContactServiceFromAPI()
-	h := handlers.New(logger, cs)
-	router := initializeRoutes(h)
-	routerWithMiddleware := recoveryMiddleware(router)
+	var err error
+	if restore == "" {
+		if restore, err = newerSnapshot(cfg); err != nil {
+			return err
+		}
+	}
+	cs, err := serveContactService(cfg, logger, restore)
+	if err != nil {
+		return err
+	}
+
+	if cfg.DevAssets {
+		static.UseDisk("static")
+		logger.Println("serving static files from disk")
+	}
+	lifecycle := internal.NewLifecycle()
+	h := handlers.New(logger, cs, cfg)
+	h.BuildTag = BuildTag
+	if cfg.SnapshotDir != "" {
+		if h.Snapshots, err = services.NewSnapshots(cs, cfg.SnapshotDir, cfg.SnapshotKeep); err != nil {
+			return err
+		}
+	}
+	if cfg.ChaosEnabled() {
+		if h.Chaos, err = internal.NewChaos(chaosRules(cfg)); err != nil {
+			return err
+		}
+		logger.Println("injecting faults, see /debug/chaos")
+	}
+	router := initializeRoutes(h, lifecycle)
+	csrf := internal.CSRF(h.RenderError, cspReportPath)
+	secure := internal.SecureHeaders(internal.CSP{
+		ReportOnly: cfg.CSPReportOnly,
+		ReportURI:  cspReportPath,
+	})
+	routerWithMiddleware := recoveryMiddleware(secure(csrf(router)), h.RenderError)
+	if h.Chaos != nil {
+		// Outermost, so that dropped connections are not recovered from.
+		routerWithMiddleware = h.Chaos.Middleware(router.ServeMux, chaosExempt...)(routerWithMiddleware)
+	}
 
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: routerWithMiddleware,
+		Addr:              ":" + cfg.Port,
+		Handler:           routerWithMiddleware,
+		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(cfg.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.IdleTimeout),
+		ErrorLog:          logger,
 	}
+	srv.RegisterOnShutdown(lifecycle.Drain) // Also ends streams of hijacked connections.
 
+	// Redirects plain HTTP to HTTPS, see cfg.RedirectPort.
+	var redirectSrv *http.Server
+	if cfg.TLS() {
+		cert, err := loadCertificate(cfg)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = internal.TLSConfig(cert)
+
+		if cfg.RedirectPort != "" {
+			redirectSrv = &http.Server{
+				Addr:              ":" + cfg.RedirectPort,
+				Handler:           internal.RedirectHTTPS(cfg.Port),
+				ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout),
+				IdleTimeout:       time.Duration(cfg.IdleTimeout),
+				ErrorLog:          logger,
+			}
+		}
+	}
+
+	// Serves profiles, runtime stats and backups, see adminRoutes.
+	var adminSrv *http.Server
+	if cfg.AdminEnabled() {
+		adminSrv = &http.Server{
+			Addr:              cfg.AdminAddr,
+			Handler:           adminRoutes(router, h),
+			ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout),
+			ErrorLog:          logger,
+		}
+	}
+
+	// Listen before reporting ready, so that a busy port fails fast.
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	serveErr := make(chan error, 3)
 	go func() {
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			logger.Fatalf("server error: %v\n", err)
+		if srv.TLSConfig != nil {
+			serveErr <- srv.ServeTLS(ln, "", "") // Certificate set by TLSConfig.
+		} else {
+			serveErr <- srv.Serve(ln)
 		}
 	}()
-	logger.Printf("listening on :%s\n", port)
+	if redirectSrv != nil {
+		go func() { serveErr <- redirectSrv.ListenAndServe() }()
+		logger.Printf("redirecting :%s to https\n", cfg.RedirectPort)
+	}
+	if adminSrv != nil {
+		go func() { serveErr <- adminSrv.ListenAndServe() }()
+		logger.Printf("admin listening on %s\n", cfg.AdminAddr)
+	}
+	if cfg.TLS() {
+		logger.Printf("listening on :%s with https\n", cfg.Port)
+	} else {
+		logger.Printf("listening on :%s\n", cfg.Port)
+	}
+	// Background goroutines run until shutdown, see background.
+	bgCtx, cancelBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		cs.RefreshCountCache(bgCtx, time.Minute)
+	}()
+	if h.Snapshots != nil && cfg.SnapshotInterval > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			h.Snapshots.Run(bgCtx, time.Duration(cfg.SnapshotInterval), func(err error) {
+				logger.Printf("error saving snapshot: %v\n", err)
+			})
+		}()
+		logger.Printf("snapshotting to %s every %s\n", cfg.SnapshotDir, time.Duration(cfg.SnapshotInterval))
+	}
+
+	lifecycle.SetReady()
 
-	<-ctx.Done() // Wait for shutdown signal
+	var errs []error
+	select {
+	case <-ctx.Done(): // Wait for shutdown signal
+	case err := <-serveErr:
+		errs = append(errs, fmt.Errorf("server error: %w", err))
+	}
+	stop() // A second signal kills the process.
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	logger.Println("draining")
+	lifecycle.Drain()
+	time.Sleep(time.Duration(cfg.DrainDelay))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		logger.Fatalf("error shutting down server: %v\n", err)
+		errs = append(errs, fmt.Errorf("error shutting down server: %w", err))
+		srv.Close() // Cut the requests left.
+	}
+	for _, other := range []*http.Server{redirectSrv, adminSrv} {
+		if other != nil && other.Shutdown(shutdownCtx) != nil {
+			other.Close()
+		}
+	}
+
+	cancelBackground()
+	background.Wait()
+
+	// Snapshot what changed since the last tick, before the data file is
+	// saved so that the data file stays the newer of the two.
+	if h.Snapshots != nil {
+		if path, err := h.Snapshots.SaveIfChanged(); err != nil {
+			logger.Printf("error saving snapshot: %v\n", err)
+		} else if path != "" {
+			logger.Printf("saved snapshot %s\n", path)
+		}
+	}
+
+	if cfg.DataFile != "" {
+		if err := cs.Save(cfg.DataFile); err != nil {
+			errs = append(errs, err)
+		} else {
+			logger.Printf("saved contacts to %s\n", cfg.DataFile)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 	logger.Println("server gracefully stopped")
+	return nil
+}
+
+// chaosExempt are the routes editing the chaos rules, never injected with
+// faults so that they can always be turned off.
+var chaosExempt = []string{"GET /debug/chaos", "POST /debug/chaos", "DELETE /debug/chaos"}
+
+// chaosRules returns the chaos rules of cfg. With cfg.DebugSleep, every
+// route without a rule is delayed by cfg.DebugSleepSecs.
+func chaosRules(cfg internal.Config) []internal.ChaosRule {
+	rules := append([]internal.ChaosRule(nil), cfg.ChaosRules...)
+	if cfg.DebugSleep && !slices.ContainsFunc(rules, func(rule internal.ChaosRule) bool { return rule.Pattern == internal.ChaosAnyRoute }) {
+		rules = append(rules, internal.ChaosRule{
+			Pattern: internal.ChaosAnyRoute,
+			Latency: internal.Duration(time.Duration(cfg.DebugSleepSecs) * time.Second),
+		})
+	}
+	return rules
+}
+
+// loadCertificate loads the TLS certificate of cfg, generating a self-signed
+// one for the addresses of this machine if requested.
+func loadCertificate(cfg internal.Config) (tls.Certificate, error) {
+	if cfg.TLSSelfSigned {
+		return internal.LoadOrCreateCertificate(cfg.TLSCertFile, cfg.TLSKeyFile, internal.LocalHosts(), time.Now())
+	}
+	return tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+}
+
+// serveContactService restores the roster from the snapshot file, if set,
+// or opens the roster of cfg.DataFile, or seeds a new one from cfg.ApiUrl,
+// saved to cfg.DataFile if set.
+func serveContactService(cfg internal.Config, logger *log.Logger, snapshot string) (*services.ContactService, error) {
+	if snapshot != "" {
+		cs := services.NewContactService(cfg)
+		f, err := os.Open(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := cs.RestoreSnapshot(f); err != nil {
+			return nil, fmt.Errorf("error restoring %s: %w", snapshot, err)
+		}
+		logger.Printf("restored contacts from %s\n", snapshot)
+		return cs, loadCustomFields(cs, cfg)
+	}
+
+	if cfg.DataFile != "" {
+		if _, err := os.Stat(cfg.DataFile); err == nil {
+			return openContactService(cfg)
+		}
+	}
+
+	cs := services.NewContactServiceFromAPI(cfg)
+	if err := loadCustomFields(cs, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.DataFile != "" {
+		logger.Printf("creating data file %s\n", cfg.DataFile)
+		return cs, cs.Save(cfg.DataFile)
+	}
+	return cs, nil
+}
+
+// newerSnapshot returns the path of the newest snapshot in
+// cfg.SnapshotDir if it is newer than cfg.DataFile, or there is none, so
+// that changes since the data file was last saved are restored after a
+// crash. It returns "" if there is no such snapshot.
+func newerSnapshot(cfg internal.Config) (string, error) {
+	if cfg.SnapshotDir == "" {
+		return "", nil
+	}
+	path, at, err := services.LatestSnapshot(cfg.SnapshotDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if cfg.DataFile != "" {
+		if info, err := os.Stat(cfg.DataFile); err == nil && !at.After(info.ModTime()) {
+			return "", nil
+		}
+	}
+	return path, nil
 }
 
 // initializeRoutes accepts a router instance instead of directly registering routes.
 //
 // Patterns can match the method, host and path of a request. See Paterns, https://pkg.go.dev/net/http#hdr-Patterns
 // [METHOD ][HOST]/[PATH]
-func initializeRoutes(h *handlers.DefaultHandler) *http.ServeMux {
-	mux := http.NewServeMux()
+func initializeRoutes(h *handlers.DefaultHandler, lifecycle *internal.Lifecycle) *routeMux {
+	mux := newRouteMux()
 
 	// Serve static files
-	mux.Handle("/static/", internal.Gzip(http.StripPrefix("/static/", http.FileServer(http.Dir("static/")))))
-	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) { http.ServeFile(w, r, "robots.txt") })
+	mux.Handle("/static/", compress(static.Assets.Handler()))
+	mux.HandleFunc("GET /robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		static.Assets.ServeFile(w, r, "robots.txt")
+	})
 
 	// Routes for pages
-	var withGzip bool = true // flag
-	mux.Handle("/", gzipMiddleware(http.HandlerFunc(h.HandleIndexPage), withGzip))
-	mux.Handle("/about", gzipMiddleware(http.HandlerFunc(h.HandleAboutPage), withGzip))
+	var withCompression bool = true // flag
+	mux.Handle("GET /{$}", compressMiddleware(h.HandleErrors(h.HandleIndexPage), withCompression))
+	mux.Handle("/about", compressMiddleware(h.HandleErrors(h.HandleAboutPage), withCompression))
+
+	// Mutations are rate limited per client IP, see Config.RateLimitCreate.
+	limiter := internal.NewRateLimiter(internal.RateLimiterOptions{
+		TrustedProxies: h.Config.TrustedProxies,
+		OnLimit:        h.RenderError,
+	})
+	limit := func(name string, perMinute int) func(http.Handler) http.Handler {
+		if perMinute == 0 {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return limiter.Limit(name, internal.Limit{Requests: perMinute, Per: time.Minute})
+	}
+	create := limit("create", h.Config.RateLimitCreate)
+	writes := limit("writes", h.Config.RateLimitWrites)
+	bulk := limit("bulk", h.Config.RateLimitBulk)
 
 	// Routes for partials
-	mux.HandleFunc("POST /contacts", h.HandleCreateContact)
-	mux.HandleFunc("GET /contacts", h.HandleReadContacts)
-	mux.HandleFunc("GET /contacts/{id}", h.HandleReadContact)
-	mux.HandleFunc("PUT /contacts/{id}", h.HandleUpdateContact)
-	mux.HandleFunc("DELETE /contacts/{id}", h.HandleDeleteContact)
-	mux.HandleFunc("GET /contacts/count", h.HandleGetContactsCount)
-	mux.HandleFunc("GET /contacts/count?active=true", h.HandleGetContactsCount)
-	mux.HandleFunc("GET /contacts/count?inactive=true", h.HandleGetContactsCount)
+	mux.Handle("POST /contacts", create(h.HandleErrors(h.HandleCreateContact)))
+	mux.Handle("GET /contacts", h.HandleErrors(h.HandleReadContacts))
+	mux.Handle("GET /contacts/{id}", h.HandleErrors(h.HandleReadContact))
+	mux.Handle("PUT /contacts/{id}", writes(h.HandleErrors(h.HandleUpdateContact)))
+	mux.Handle("DELETE /contacts/{id}", writes(h.HandleErrors(h.HandleDeleteContact)))
+	mux.Handle("GET /contacts/count", h.HandleErrors(h.HandleGetContactsCount))
+	mux.Handle("GET /contacts/count?active=true", h.HandleErrors(h.HandleGetContactsCount))
+	mux.Handle("GET /contacts/count?inactive=true", h.HandleErrors(h.HandleGetContactsCount))
+
+	// Routes for duplicate detection
+	mux.Handle("GET /contacts/duplicates", compressMiddleware(h.HandleErrors(h.HandleDuplicatesPage), withCompression))
+	mux.Handle("POST /contacts/duplicates/merge", bulk(h.HandleErrors(h.HandleMergeContacts)))
+
+	// Routes for tags
+	mux.Handle("GET /tags", compressMiddleware(h.HandleErrors(h.HandleTagsPage), withCompression))
+	mux.Handle("PUT /tags/{tag}", bulk(h.HandleErrors(h.HandleRenameTag)))
+	mux.Handle("DELETE /tags/{tag}", bulk(h.HandleErrors(h.HandleDeleteTag)))
+	mux.Handle("POST /contacts/tags", bulk(h.HandleErrors(h.HandleBulkTag)))
+
+	// Routes for attendance stats and reports
+	mux.Handle("GET /stats", compressMiddleware(h.HandleErrors(h.HandleStatsPage), withCompression))
+	mux.Handle("GET /stats/report.pdf", compressMiddleware(h.HandleErrors(h.HandleAttendanceReport), withCompression))
+
+	// Routes for custom fields
+	mux.Handle("GET /admin/fields", compressMiddleware(h.HandleErrors(h.HandleFieldsPage), withCompression))
+	mux.Handle("POST /admin/fields", writes(h.HandleErrors(h.HandleDefineField)))
+	mux.Handle("DELETE /admin/fields/{key}", writes(h.HandleErrors(h.HandleRemoveField)))
+
+	// Routes for roster snapshots
+	mux.Handle("GET /admin/backups", compressMiddleware(h.HandleErrors(h.HandleBackupsPage), withCompression))
+	mux.Handle("POST /admin/backup", bulk(h.HandleErrors(h.HandleBackup)))
+
+	// Routes for import, export and the JSON API
+	mux.Handle("GET /contacts/export.csv", compressMiddleware(h.HandleErrors(h.HandleExportCSV), withCompression))
+	mux.Handle("GET /contacts/export.vcf", compressMiddleware(h.HandleErrors(h.HandleExportVCard), withCompression))
+	mux.Handle("GET /contacts/export.xlsx", h.HandleErrors(h.HandleExportXLSX)) // Zipped already.
+	mux.Handle("POST /contacts/import", bulk(h.HandleErrors(h.HandleImportContacts)))
+	mux.Handle("GET /api/contacts", compressMiddleware(h.HandleErrors(h.HandleAPIContacts), withCompression))
+	mux.Handle("GET /api/fields", h.HandleErrors(h.HandleAPIFields))
 
 	// Routes for intermediate requests
-	mux.HandleFunc("GET /contacts/{id}/edit", h.HandleGetUpdateContactForm)
+	mux.Handle("GET /contacts/{id}/edit", h.HandleErrors(h.HandleGetUpdateContactForm))
+
+	// Routes for fault injection, see chaosExempt
+	if h.Chaos != nil {
+		mux.Handle(chaosExempt[0], h.HandleErrors(h.HandleChaosPage))
+		mux.Handle(chaosExempt[1], h.HandleErrors(h.HandleSetChaosRule))
+		mux.Handle(chaosExempt[2], h.HandleErrors(h.HandleRemoveChaosRule))
+	}
 
-	mux.HandleFunc("/healthcheck", h.HandleHealthcheck)
+	mux.Handle("/healthcheck", h.HandleErrors(h.HandleHealthcheck))
+	mux.Handle("GET /readyz", lifecycle.ReadyHandler())
+	mux.Handle("POST "+cspReportPath, h.HandleErrors(h.HandleCSPReport))
+
+	// Every other path
+	mux.Handle("/", h.HandleErrors(h.HandleNotFound))
 
 	return mux
 }
 
 // Fixme: This somehow overides timeout of cancel context
-func recoveryMiddleware(next http.Handler) http.Handler {
+func recoveryMiddleware(next http.Handler, renderError func(w http.ResponseWriter, r *http.Request, err error)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("application panic: %v", err)
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				renderError(w, r, fmt.Errorf("application panic: %v", err))
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
-func gzipMiddleware(next http.Handler, withGzip bool) http.Handler {
-	if withGzip {
-		return internal.Gzip(next)
+// compress compresses responses worth it, sharing its pool of writers
+// across routes.
+var compress = internal.Compress(internal.Compression{MinSize: 1024})
+
+func compressMiddleware(next http.Handler, withCompression bool) http.Handler {
+	if withCompression {
+		return compress(next)
 	}
 	return next
 }
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates/components"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandleBackupsPage handles HTTP GET - /admin/backups.
+//
+// Lists the saved snapshots. Downloading and restoring the roster is left
+// to the admin listener, see HandleDownloadBackup and HandleRestoreSnapshot.
+func (h *DefaultHandler) HandleBackupsPage(w http.ResponseWriter, r *http.Request) error {
+	snapshots, err := h.snapshotNames()
+	if err != nil {
+		return err
+	}
+
+	html := pages.BackupsPage(snapshots, h.Snapshots != nil, h.adminAddr())
+	return h.renderPage(w, r, http.StatusOK, "Backups", html)
+}
+
+// HandleBackup handles HTTP POST - /admin/backup.
+//
+// Saves a snapshot of the roster to Config.SnapshotDir. Responds with the
+// updated BackupsAdmin.
+func (h *DefaultHandler) HandleBackup(w http.ResponseWriter, r *http.Request) error {
+	if h.Snapshots == nil {
+		return NewHTTPError(http.StatusNotFound, "snapshots are off: start the server with -snapshot-dir")
+	}
+
+	path, err := h.Snapshots.Save()
+	if err != nil {
+		return err
+	}
+	h.Log.Printf("saved snapshot %s\n", path)
+
+	snapshots, err := h.snapshotNames()
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return h.renderView(w, r, components.BackupsAdmin(snapshots, true, h.adminAddr()))
+}
+
+// HandleDownloadBackup handles HTTP GET - /admin/backup on the admin
+// listener.
+//
+// Downloads a snapshot of the roster as it is now, whether or not
+// snapshots are saved.
+func (h *DefaultHandler) HandleDownloadBackup(w http.ResponseWriter, r *http.Request) error {
+	filename := fmt.Sprintf("snapshot-%s.json", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.ContactService.WriteSnapshot(w); err != nil {
+		h.Log.Printf("error writing snapshot: %v", err)
+	}
+	return nil
+}
+
+// HandleDownloadSnapshot handles HTTP GET - /admin/backups/{name} on the
+// admin listener.
+func (h *DefaultHandler) HandleDownloadSnapshot(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+	if h.Snapshots == nil || !services.IsSnapshotName(name) {
+		return NewHTTPError(http.StatusNotFound, "snapshot not found")
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, filepath.Join(h.Snapshots.Dir, name))
+	return nil
+}
+
+// HandleRestoreSnapshot handles HTTP POST - /admin/restore on the admin
+// listener.
+//
+// Expects a snapshot written by HandleDownloadBackup, HandleBackup or as a
+// data file, as a JSON body, e.g.
+//
+//	curl -H 'Content-Type: application/json' --data-binary @snapshot.json localhost:6060/admin/restore
+//
+// The JSON content type keeps web pages from posting snapshots to the
+// listener, as it takes a CORS preflight that is never granted. It replaces
+// every contact and custom field, after snapshotting the roster when
+// snapshots are on, so that a mistaken restore can be undone.
+func (h *DefaultHandler) HandleRestoreSnapshot(w http.ResponseWriter, r *http.Request) error {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return NewHTTPError(http.StatusUnsupportedMediaType, "send the snapshot as application/json")
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if h.Snapshots != nil {
+		path, err := h.Snapshots.SaveIfChanged()
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			h.Log.Printf("saved snapshot %s before restoring\n", path)
+		}
+	}
+
+	if err := h.ContactService.RestoreSnapshot(r.Body); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return maxBytesErr
+		}
+		return NewHTTPError(http.StatusUnprocessableEntity, "%v", err)
+	}
+
+	count := h.ContactService.Count()
+	h.Log.Printf("restored %d contacts\n", count)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "restored %d contacts\n", count)
+	return nil
+}
+
+// adminAddr returns the address of the admin listener, or "" if it is off.
+func (h *DefaultHandler) adminAddr() string {
+	if !h.Config.AdminBackups {
+		return ""
+	}
+	return h.Config.AdminAddr
+}
+
+// snapshotNames lists the saved snapshots, or none if snapshots are off.
+func (h *DefaultHandler) snapshotNames() ([]string, error) {
+	if h.Snapshots == nil {
+		return nil, nil
+	}
+	return h.Snapshots.List()
+}
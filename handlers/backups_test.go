@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lloydlobo/go-headcount/services"
+)
+
+func TestHandleRestoreSnapshot(t *testing.T) {
+	var snapshot bytes.Buffer
+	if err := newTestHandler(services.FakeContacts(4, 1)...).ContactService.WriteSnapshot(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCount   int
+	}{
+		{"snapshot", "application/json", snapshot.String(), http.StatusOK, 4},
+		{"form post", "multipart/form-data; boundary=x", snapshot.String(), http.StatusUnsupportedMediaType, 2},
+		{"invalid snapshot", "application/json; charset=utf-8", `{"format":"headcount-snapshot","version":-1}`, http.StatusUnprocessableEntity, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHandler(services.FakeContacts(2, 2)...)
+			r := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(test.body))
+			r.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+
+			h.HandleErrors(h.HandleRestoreSnapshot).ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d: %s", w.Code, test.wantStatus, w.Body)
+			}
+			if got := h.ContactService.Count(); got != test.wantCount {
+				t.Errorf("got %d contacts, want %d", got, test.wantCount)
+			}
+		})
+	}
+}
+
+func TestHandleCookieSessionKeepsSavedRoster(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataFile    string
+		snapshotDir string
+		wantCount   int
+	}{
+		{"demo roster", "", "", 0},
+		{"data file", "contacts.json", "", 3},
+		{"snapshots", "", "snapshots", 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHandler(services.FakeContacts(3, 1)...)
+			h.Config.DataFile, h.Config.SnapshotDir = test.dataFile, test.snapshotDir
+
+			w := httptest.NewRecorder()
+			if err := h.handleCookieSession(w, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+				t.Fatal(err)
+			}
+			if len(w.Result().Cookies()) != 1 {
+				t.Error("got no session cookie")
+			}
+			if got := h.ContactService.Count(); got != test.wantCount {
+				t.Errorf("got %d contacts after a new session, want %d", got, test.wantCount)
+			}
+		})
+	}
+}
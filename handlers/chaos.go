@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/templates/components"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandleChaosPage handles HTTP GET - /debug/chaos.
+func (h *DefaultHandler) HandleChaosPage(w http.ResponseWriter, r *http.Request) error {
+	html := pages.ChaosPage(h.Chaos.Rules())
+	return h.renderPage(w, r, http.StatusOK, "Chaos", html)
+}
+
+// HandleSetChaosRule handles HTTP POST - /debug/chaos.
+//
+// Rates are read as percentages, and latency as a duration, e.g. "1.5s".
+func (h *DefaultHandler) HandleSetChaosRule(w http.ResponseWriter, r *http.Request) error {
+	errs := models.FieldErrors{}
+	rule := internal.ChaosRule{Pattern: strings.TrimSpace(r.FormValue("pattern"))}
+
+	if rule.Pattern == "" {
+		errs.Add("pattern", "route is required, e.g. POST /contacts or *")
+	}
+	if latency := strings.TrimSpace(r.FormValue("latency")); latency != "" {
+		d, err := time.ParseDuration(latency)
+		if err != nil || d < 0 {
+			errs.Add("latency", "want a duration, e.g. 500ms or 2s")
+		}
+		rule.Latency = internal.Duration(d)
+	}
+	for _, rate := range []struct {
+		field string
+		dst   *float64
+	}{
+		{"error_rate", &rule.ErrorRate},
+		{"unavailable_rate", &rule.UnavailableRate},
+		{"drop_rate", &rule.DropRate},
+	} {
+		value := strings.TrimSpace(r.FormValue(rate.field))
+		if value == "" {
+			continue
+		}
+		percent, err := strconv.ParseFloat(value, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			errs.Add(rate.field, "want a percentage from 0 to 100")
+		}
+		*rate.dst = percent / 100
+	}
+
+	if !errs.Any() {
+		if err := h.Chaos.SetRule(rule); err != nil {
+			errs.Add("drop_rate", err.Error()) // Rates adding up to more than 100%.
+		}
+	}
+	if errs.Any() {
+		return h.renderFormErrors(w, r, "#chaos-admin", components.ChaosAdmin(h.Chaos.Rules(), errs))
+	}
+
+	h.Log.Printf("chaos: set %+v\n", rule)
+	w.WriteHeader(http.StatusOK)
+	return h.renderView(w, r, components.ChaosAdmin(h.Chaos.Rules(), nil))
+}
+
+// HandleRemoveChaosRule handles HTTP DELETE - /debug/chaos?pattern=.
+func (h *DefaultHandler) HandleRemoveChaosRule(w http.ResponseWriter, r *http.Request) error {
+	pattern := r.URL.Query().Get("pattern")
+	h.Chaos.RemoveRule(pattern)
+	h.Log.Printf("chaos: removed %q\n", pattern)
+
+	w.WriteHeader(http.StatusOK)
+	return h.renderView(w, r, components.ChaosAdmin(h.Chaos.Rules(), nil))
+}
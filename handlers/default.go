@@ -27,18 +27,24 @@ import (
 	"errors"
 	"fmt"
 	"html"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/a-h/templ"
 	"github.com/google/uuid"
 
 	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/internal/htmx"
 	"github.com/lloydlobo/go-headcount/models"
 	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates"
 	"github.com/lloydlobo/go-headcount/templates/components"
 	"github.com/lloydlobo/go-headcount/templates/pages"
 )
@@ -55,13 +61,29 @@ type ContactService interface {
 	Count() int
 	CountByStatus(s models.Status) (count int)
 	ResetContacts()
+	FindDuplicates() []models.DuplicateGroup
+	Merge(req services.MergeRequest) (models.Contact, error)
+	Fields() models.FieldDefinitions
+	DefineField(fd models.FieldDefinition) error
+	RemoveField(key string) error
+	Import(contacts models.Contacts) (added, updated int)
+	Filter(f models.ContactFilter) models.Contacts
+	CountFiltered(f models.ContactFilter) (count int)
+	Tags() []models.TagCount
+	TagContacts(ids []uuid.UUID, tag string) (changed int)
+	UntagContacts(ids []uuid.UUID, tag string) (changed int)
+	RenameTag(tag, newTag string) (changed int)
+	DeleteTag(tag string) (changed int)
+	WriteSnapshot(w io.Writer) error
+	RestoreSnapshot(r io.Reader) error
 }
 
-// New creates a new DefaultHandler with the given ContactService.
-func New(logger *log.Logger, cs ContactService) *DefaultHandler {
+// New creates a new DefaultHandler with the given ContactService and config.
+func New(logger *log.Logger, cs ContactService, cfg internal.Config) *DefaultHandler {
 	return &DefaultHandler{
 		Log:            logger,
 		ContactService: cs,
+		Config:         cfg,
 	}
 }
 
@@ -69,30 +91,37 @@ func New(logger *log.Logger, cs ContactService) *DefaultHandler {
 type DefaultHandler struct {
 	Log            *log.Logger
 	ContactService ContactService
+	Config         internal.Config
+	Chaos          *internal.Chaos     // Fault injection, nil unless Config.ChaosEnabled.
+	BuildTag       string              // Version of the server, footing reports.
+	Snapshots      *services.Snapshots // Saved roster snapshots, nil unless Config.SnapshotDir.
+
+	sessions atomic.Int64 // Sessions started, see handleCookieSession.
 }
 
+// Sessions returns the number of browser sessions started since the
+// handler was created.
+func (h *DefaultHandler) Sessions() int64 { return h.sessions.Load() }
+
 // HandleIndexPage handles requests for GET "/index" page.
-func (h *DefaultHandler) HandleIndexPage(w http.ResponseWriter, r *http.Request) {
+func (h *DefaultHandler) HandleIndexPage(w http.ResponseWriter, r *http.Request) error {
 	if err := h.handleCookieSession(w, r); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
-	indexHTML := pages.IndexPage()
-	h.renderView(w, r, indexHTML)
+	filter := models.ContactFilter{Tag: r.URL.Query().Get("tag")}
+	indexHTML := pages.IndexPage(h.ContactService.Fields(), h.ContactService.Tags(), filter)
+	return h.renderPage(w, r, http.StatusOK, "Home", indexHTML)
 }
 
 // HandleAboutPage handles requests for GET "/about" page.
-func (h *DefaultHandler) HandleAboutPage(w http.ResponseWriter, r *http.Request) {
+func (h *DefaultHandler) HandleAboutPage(w http.ResponseWriter, r *http.Request) error {
 	if err := h.handleCookieSession(w, r); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
 	aboutHTML := pages.AboutPage()
-	h.renderView(w, r, aboutHTML)
+	return h.renderPage(w, r, http.StatusOK, "About", aboutHTML)
 }
 
 // HandleReadContacts handles requests for contact partials.
@@ -102,102 +131,134 @@ func (h *DefaultHandler) HandleAboutPage(w http.ResponseWriter, r *http.Request)
 //	<span hx-get="/contacts" hx-target="#hx-contacts" hx-swap="beforeend" hx-trigger="load"></span>
 //
 // So `beforeend` ensures that swap does not mutate the previous elements.
-func (h *DefaultHandler) HandleReadContacts(w http.ResponseWriter, r *http.Request) {
-	contacts, err := h.ContactService.Get()
-	if err != nil {
-		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		return
+//
+// Filters:
+//   - "GET /contacts?tag=vip"
+func (h *DefaultHandler) HandleReadContacts(w http.ResponseWriter, r *http.Request) error {
+	filter := models.ContactFilter{Tag: r.URL.Query().Get("tag")}
+	contacts := h.ContactService.Filter(filter)
+
+	// Keep the address bar in sync with components.TagFilter, so that a
+	// filtered table can be bookmarked and navigated back to.
+	if htmx.TriggerID(r) == "tag-filter" {
+		location := "/"
+		if filter.Tag != "" {
+			location += "?" + url.Values{"tag": {filter.Tag}}.Encode()
+		}
+		htmx.PushURL(w, location)
 	}
 
-	h.renderView(w, r, components.ContactsTable(contacts))
+	return h.renderView(w, r, components.ContactsTable(contacts, h.ContactService.Fields()))
 }
 
-// HandleReadContact handles HTTP GET - /contacts/{id}.
-func (h *DefaultHandler) HandleReadContact(w http.ResponseWriter, r *http.Request) {
-	uuidID, err := uuid.Parse(r.PathValue("id")) // Note: Parse should not be used to validate strings as it parses non-standard encodings
+// HandleReadContact handles HTTP GET - /contacts/{id}, and the vCard of
+// the contact at /contacts/{id}.vcf.
+func (h *DefaultHandler) HandleReadContact(w http.ResponseWriter, r *http.Request) error {
+	id, vcard := strings.CutSuffix(r.PathValue("id"), ".vcf") // GET /contacts/{id}.vcf, a pattern ServeMux cannot express.
+
+	uuidID, err := uuid.Parse(id) // Note: Parse should not be used to validate strings as it parses non-standard encodings
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return NewHTTPError(http.StatusBadRequest, "invalid contact id: %v", err)
 	}
 
 	contact := h.ContactService.CrudOps(services.ActionEdit, models.Contact{ID: uuidID})
+	if contact.ID != uuidID {
+		return ErrNotFound
+	}
+
+	if vcard {
+		filename := internal.Slug(contact.Name)
+		if filename == "" {
+			filename = "contact"
+		}
+		return h.renderVCard(w, models.Contacts{contact}, filename+".vcf")
+	}
 
 	w.WriteHeader(http.StatusOK)
-	html := components.ContactRow(contact)
-	h.renderView(w, r, html)
+	html := components.ContactRow(contact, h.ContactService.Fields())
+	return h.renderView(w, r, html)
 }
 
 // HandleCreateContact handles HTTP POST - /contacts
-func (h *DefaultHandler) HandleCreateContact(w http.ResponseWriter, r *http.Request) {
-	contact, err := h.parseContactFromRequestForm(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+func (h *DefaultHandler) HandleCreateContact(w http.ResponseWriter, r *http.Request) error {
+	contact, errs := h.parseContactFromRequestForm(r)
+	if errs.Any() {
+		return h.renderFormErrors(w, r, "#contact-post-form", components.ContactPostForm(contact, errs, h.ContactService.Fields(), models.InitialStatuses))
 	}
 
-	_ = h.ContactService.CrudOps(services.ActionCreate, contact)
+	if !slices.Contains(models.InitialStatuses, contact.Status) {
+		errs := models.FieldErrors{}
+		errs.Add("status", fmt.Sprintf("new contacts cannot start as %s", contact.Status.Label()))
+		return h.renderFormErrors(w, r, "#contact-post-form", components.ContactPostForm(contact, errs, h.ContactService.Fields(), models.InitialStatuses))
+	}
+
+	created := h.ContactService.CrudOps(services.ActionCreate, contact)
 
 	contacts, err := h.ContactService.Get()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
+		return err
 	}
 
+	h.triggerContactsChanged(w, toastOK, fmt.Sprintf("Added %s", created.Name))
 	w.WriteHeader(http.StatusOK)
-	html := components.ContactsTable(contacts)
-	h.renderView(w, r, html)
+	html := components.ContactsTable(contacts, h.ContactService.Fields())
+	return h.renderView(w, r, html)
 }
 
 // HandleGetUpdateContactForm handles HTTP GET - /contacts/{id}/edit.
 //
 // Renders a slideout aside with a form pre-filled with contact of id's details.
-func (h *DefaultHandler) HandleGetUpdateContactForm(w http.ResponseWriter, r *http.Request) {
+func (h *DefaultHandler) HandleGetUpdateContactForm(w http.ResponseWriter, r *http.Request) error {
 	uuidID, err := uuid.Parse(r.PathValue("id")) // Note: Parse should not be used to validate strings as it parses non-standard encodings.
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return NewHTTPError(http.StatusBadRequest, "invalid contact id: %v", err)
 	}
 
 	contact := h.ContactService.CrudOps(services.ActionEdit, models.Contact{ID: uuidID})
+	if contact.ID != uuidID {
+		return ErrNotFound
+	}
 
 	w.WriteHeader(http.StatusOK)
-	html := components.Slideout(components.ContactPutForm(contact), "Close", true)
-	h.renderView(w, r, html)
+	statuses := services.NextStatuses(contact.Status)
+	html := components.Slideout(components.ContactPutForm(contact, nil, h.ContactService.Fields(), statuses), "Close", true)
+	return h.renderView(w, r, html)
 }
 
 // HandleUpdateContact handles HTTP PUT - /contacts/{id}.
-func (h *DefaultHandler) HandleUpdateContact(w http.ResponseWriter, r *http.Request) {
-	contact, err := h.parseContactFromRequestForm(r)
+func (h *DefaultHandler) HandleUpdateContact(w http.ResponseWriter, r *http.Request) error {
+	contact, errs := h.parseContactFromRequestForm(r)
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if errs.Has("id") {
+		return NewHTTPError(http.StatusBadRequest, "invalid contact id: %s", errs.Get("id")[0])
 	}
 
 	oldContact := h.ContactService.CrudOps(services.ActionEdit, contact)
-
 	if oldContact.ID != contact.ID {
-		err := errors.New("error matching records")
-		h.Log.Println(err.Error(), http.StatusNotFound)
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
+		return NewHTTPError(http.StatusNotFound, "error matching records")
+	}
+
+	if err := services.CheckTransition(oldContact.Status, contact.Status); err != nil && !errs.Has("status") {
+		errs.Add("status", err.Error())
+	}
+
+	if errs.Any() {
+		statuses := services.NextStatuses(oldContact.Status)
+		return h.renderFormErrors(w, r, "#contact-put-form", components.ContactPutForm(contact, errs, h.ContactService.Fields(), statuses))
 	}
 
 	updatedContact := h.ContactService.CrudOps(services.ActionUpdate, contact)
 
 	// If update action returns empty value, incorrect email or contact was deleted,
 	// due to empty name (courtesy of todomvc style action.)
-	var emptyContact models.Contact
-	if updatedContact == emptyContact {
-		err := errors.New("something went wrong when updating record")
-		h.Log.Println(err.Error(), http.StatusInternalServerError)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if updatedContact.ID == uuid.Nil {
+		return errors.New("something went wrong when updating record")
 	}
 
+	h.triggerContactsChanged(w, toastOK, fmt.Sprintf("Saved %s", updatedContact.Name))
 	w.WriteHeader(http.StatusOK)
-	html := components.ContactRow(updatedContact)
-	h.renderView(w, r, html)
+	html := components.ContactRow(updatedContact, h.ContactService.Fields())
+	return h.renderView(w, r, html)
 }
 
 // HandleDeleteContact handles HTTP DELETE - /contacts/{id}.
@@ -208,96 +269,190 @@ func (h *DefaultHandler) HandleUpdateContact(w http.ResponseWriter, r *http.Requ
 //
 // Consider options like `hx-swap='none'` for preserving the current state
 // or `hx-swap='delete'` for removing elements in response to the request.
-func (h *DefaultHandler) HandleDeleteContact(w http.ResponseWriter, r *http.Request) {
+func (h *DefaultHandler) HandleDeleteContact(w http.ResponseWriter, r *http.Request) error {
 	uuidID, err := uuid.Parse(r.PathValue("id")) // Note: Parse should not be used to validate strings as it parses non-standard encodings.
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return NewHTTPError(http.StatusBadRequest, "invalid contact id: %v", err)
 	}
 
 	_ = h.ContactService.CrudOps(services.ActionDelete, models.Contact{ID: uuidID})
 
+	h.triggerContactsChanged(w, toastInfo, "Contact deleted")
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "")
+	return nil
+}
+
+// parseContactFilter reads the "tag" query parameter and a single status,
+// either as "status" or as a status query key set to "true", e.g.
+// "checkedout=true" or the legacy "active=true".
+func parseContactFilter(query url.Values) (models.ContactFilter, error) {
+	filter := models.ContactFilter{Tag: query.Get("tag")}
+
+	var selected []models.Status
+	if raw := query.Get("status"); raw != "" {
+		status, err := models.ParseStatus(raw)
+		if err != nil {
+			return filter, NewHTTPError(http.StatusBadRequest, "invalid query parameters: %v", err)
+		}
+		selected = append(selected, status)
+	}
+	for _, status := range append(slices.Clone(models.Statuses), models.StatusActive, models.StatusInactive) {
+		if query.Get(status.QueryParam()) == "true" {
+			selected = append(selected, status)
+		}
+	}
+
+	if len(selected) > 1 {
+		return filter, NewHTTPError(http.StatusBadRequest, "invalid query parameters: filter by a single status")
+	}
+	if len(selected) == 1 {
+		filter.Status = selected[0]
+	}
+	return filter, nil
 }
 
 // HandleGetContactsCount handles HTTP GET requests to /contacts/count
-// with optional filtering by active/inactive status.
+// with optional filtering by status and tag.
 //
 // Filters:
-//   - "GET /contacts/count?active=true"
-//   - "GET /contacts/count?inactive=true"
-func (h *DefaultHandler) HandleGetContactsCount(w http.ResponseWriter, r *http.Request) {
+//   - "GET /contacts/count?status=checkedin"
+//   - "GET /contacts/count?checkedout=true" (any status query key)
+//   - "GET /contacts/count?active=true" (checked in)
+//   - "GET /contacts/count?inactive=true" (not checked in)
+//   - "GET /contacts/count?tag=vip&active=true"
+func (h *DefaultHandler) HandleGetContactsCount(w http.ResponseWriter, r *http.Request) error {
 	if r.Method != http.MethodGet {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
+		return NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
 	}
 
-	query := r.URL.Query()
-	active, inactive := query.Get(models.StatusActiveQueryKey), query.Get(models.StatusInactiveQueryKey)
-
-	if active != "" && inactive != "" {
-		http.Error(w, "invalid query parameters: use either 'active' or 'inactive'", http.StatusBadRequest)
-		return
+	filter, err := parseContactFilter(r.URL.Query())
+	if err != nil {
+		return err
 	}
 
 	var count int
 
-	if active == "true" {
-		count = h.ContactService.CountByStatus(models.StatusActive)
-	} else if inactive == "true" {
-		count = h.ContactService.CountByStatus(models.StatusInactive)
-	} else {
+	if filter == (models.ContactFilter{}) {
 		count = h.ContactService.Count()
+	} else if filter.Tag == "" {
+		count = h.ContactService.CountByStatus(filter.Status)
+	} else {
+		count = h.ContactService.CountFiltered(filter)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "%d", count)
+	return nil
 }
 
-// TODO: use with central error handling middleware
+// HandleDuplicatesPage handles HTTP GET - /contacts/duplicates.
+//
+// Renders contacts grouped by probable duplicates, each group with a merge form.
+func (h *DefaultHandler) HandleDuplicatesPage(w http.ResponseWriter, r *http.Request) error {
+	groups := h.ContactService.FindDuplicates()
 
-func (h *DefaultHandler) HandleNotFound(w http.ResponseWriter, r *http.Request) { // 404
-	w.WriteHeader(http.StatusNotFound)
-	html := pages.NotFoundPage()
-	h.renderView(w, r, html)
+	html := pages.DuplicatesPage(groups)
+	return h.renderPage(w, r, http.StatusOK, "Duplicates", html)
 }
 
-// TODO: use with central error handling middleware
+// HandleMergeContacts handles HTTP POST - /contacts/duplicates/merge.
+//
+// Expects the form rendered by components.DuplicateGroups: every contact of
+// the group in "ids", the survivor in "keep", and one "pick_<field>" per
+// column naming the contact whose value is kept. Responds with the
+// remaining duplicate groups.
+func (h *DefaultHandler) HandleMergeContacts(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid form: %v", err)
+	}
 
-func (h *DefaultHandler) HandlerInternalServerError(w http.ResponseWriter, r *http.Request) { // 500
-	w.WriteHeader(http.StatusInternalServerError)
-	html := pages.ServerErrorPage()
-	h.renderView(w, r, html)
+	var (
+		req services.MergeRequest
+		err error
+	)
+
+	if req.KeepID, err = uuid.Parse(r.PostForm.Get("keep")); err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid contact to keep: %v", err)
+	}
+
+	for _, id := range r.PostForm["ids"] {
+		uuidID, err := uuid.Parse(id)
+		if err != nil {
+			return NewHTTPError(http.StatusBadRequest, "invalid contact id: %v", err)
+		}
+		req.IDs = append(req.IDs, uuidID)
+	}
+
+	req.Picks = make(map[string]uuid.UUID)
+	for _, field := range services.MergeFields {
+		pick := r.PostForm.Get("pick_" + field)
+		if pick == "" {
+			continue
+		}
+		if req.Picks[field], err = uuid.Parse(pick); err != nil {
+			return NewHTTPError(http.StatusBadRequest, "invalid pick for %s: %v", field, err)
+		}
+	}
+
+	merged, err := h.ContactService.Merge(req)
+	if err != nil {
+		return err
+	}
+
+	h.triggerContactsChanged(w, toastOK, fmt.Sprintf("Merged %d contacts into %s", len(req.IDs), merged.Name))
+	w.WriteHeader(http.StatusOK)
+	html := components.DuplicateGroups(h.ContactService.FindDuplicates())
+	return h.renderView(w, r, html)
 }
 
-func (h *DefaultHandler) HandleHealthcheck(w http.ResponseWriter, r *http.Request) { // "/healthcheck"
+func (h *DefaultHandler) HandleHealthcheck(w http.ResponseWriter, r *http.Request) error { // "/healthcheck"
 	w.Header().Set("Content-Type", "application/json")
 
 	jsonResponse := map[string]string{"status": "ok"}
 
-	if err := json.NewEncoder(w).Encode(jsonResponse); err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		return
-	}
+	return json.NewEncoder(w).Encode(jsonResponse)
 }
 
 // renderView renders the provided templ.Component to http.ResponseWriter with
 // text/html content type.
-func (h *DefaultHandler) renderView(w http.ResponseWriter, r *http.Request, component templ.Component) {
+func (h *DefaultHandler) renderView(w http.ResponseWriter, r *http.Request, component templ.Component) error {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := component.Render(r.Context(), w); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	return component.Render(r.Context(), w)
 }
 
-// parseContactFromRequestForm parses contact data from the request form.
-func (h *DefaultHandler) parseContactFromRequestForm(r *http.Request) (models.Contact, error) {
+// headerTitle is read by static/js/htmx.title.js to update the document
+// title after htmx swapped in a page fragment.
+const headerTitle = "HX-Title"
 
-	validationError := func(field string, err error) error {
-		return fmt.Errorf("validation error for %s: %v", field, err)
+// renderPage renders a page of package pages titled title with status.
+//
+// Requests made by htmx, e.g. hx-boost navigation, get the body fragment
+// along with the title in the HX-Title header, while direct navigation and
+// htmx history restores get the full Base layout. See pages.Page.
+func (h *DefaultHandler) renderPage(w http.ResponseWriter, r *http.Request, status int, title string, page templ.Component) error {
+	fragment := htmx.IsRequest(r) && !htmx.IsHistoryRestore(r)
+	title = "Headcount | " + title
+
+	w.Header().Add("Vary", htmx.HeaderRequest)
+	if fragment {
+		w.Header().Set(headerTitle, title)
 	}
+	w.WriteHeader(status)
+
+	ctx := templates.WithFragment(templates.WithPageTitle(r.Context(), title), fragment)
+	return h.renderView(w, r.WithContext(ctx), page)
+}
+
+// parseContactFromRequestForm parses contact data from the request form.
+//
+// Validation failures are collected per form field rather than stopping at
+// the first one, so that the form can show every message at once. On failure
+// the returned contact holds the submitted values for re-rendering the form.
+// The returned FieldErrors is never nil, so callers may add to it.
+func (h *DefaultHandler) parseContactFromRequestForm(r *http.Request) (models.Contact, models.FieldErrors) {
+	errs := models.FieldErrors{}
 
 	// Extract form values and sanitize them
 	id := strings.TrimSpace(html.EscapeString(r.FormValue("id")))
@@ -305,6 +460,9 @@ func (h *DefaultHandler) parseContactFromRequestForm(r *http.Request) (models.Co
 	email := strings.TrimSpace(html.EscapeString(r.FormValue("email")))
 	phone := strings.TrimSpace(html.EscapeString(r.FormValue("phone")))
 	statusRaw := strings.TrimSpace(html.EscapeString(r.FormValue("status")))
+	if statusRaw == "" {
+		statusRaw = models.StatusRegistered.String()
+	}
 
 	var (
 		err    error
@@ -317,16 +475,28 @@ func (h *DefaultHandler) parseContactFromRequestForm(r *http.Request) (models.Co
 		uuidID = uuid.New()
 	} else {
 		if uuidID, err = uuid.Parse(id); err != nil {
-			return models.Contact{}, validationError("ID", err)
+			errs.Add("id", err.Error())
 		}
 	}
 
-	if err := internal.ValidateEmail(email); err != nil {
-		return models.Contact{}, validationError("Email", err)
+	if name == "" {
+		errs.Add("name", "name is required")
 	}
 
-	if status, err = (models.StatusParser{}.FormCheckboxValue(statusRaw)); err != nil {
-		return models.Contact{}, validationError("Status", err)
+	if email == "" {
+		errs.Add("email", "email is required")
+	} else if err := internal.ValidateEmail(email); err != nil {
+		errs.Add("email", err.Error())
+	}
+
+	if normalized, err := internal.NormalizePhone(phone, h.Config.PhoneRegion); err != nil {
+		errs.Add("phone", err.Error())
+	} else {
+		phone = normalized
+	}
+
+	if status, err = models.ParseStatus(statusRaw); err != nil {
+		errs.Add("status", err.Error())
 	}
 
 	contact := models.Contact{
@@ -335,21 +505,71 @@ func (h *DefaultHandler) parseContactFromRequestForm(r *http.Request) (models.Co
 		Email:  email,
 		Phone:  phone,
 		Status: status,
+		Tags:   models.ParseTags(r.FormValue("tags")),
+	}
+
+	for _, fd := range h.ContactService.Fields() {
+		raw := r.FormValue(fd.FormName())
+
+		value, err := fd.Normalize(raw)
+		if err != nil {
+			errs.Add(fd.FormName(), err.Error())
+			value = strings.TrimSpace(raw)
+		}
+		if value != "" {
+			if contact.Custom == nil {
+				contact.Custom = make(map[string]string)
+			}
+			contact.Custom[fd.Key] = value
+		}
 	}
 
-	return contact, nil
+	return contact, errs
 }
 
+// renderFormErrors responds with http.StatusUnprocessableEntity and form, which
+// replaces the submitted form identified by formSelector in place.
+//
+// htmx targets the form's hx-target on success, so HX-Retarget and HX-Reswap
+// redirect this error response back onto the form itself.
+func (h *DefaultHandler) renderFormErrors(w http.ResponseWriter, r *http.Request, formSelector string, form templ.Component) error {
+	htmx.Retarget(w, formSelector)
+	htmx.Reswap(w, "outerHTML")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	return h.renderView(w, r, form)
+}
+
+// Toast levels shown by static/js/htmx.toast.js, matching missing.css
+// colorways.
+const (
+	toastOK   = "ok"
+	toastInfo = "info"
+)
+
+// triggerContactsChanged makes the client refresh views listening for the
+// "contactsChanged" event, e.g. the counts on the index page, and shows
+// message in a toast. Call it before writing the response status.
+func (h *DefaultHandler) triggerContactsChanged(w http.ResponseWriter, level, message string) {
+	if err := htmx.Trigger(w, "contactsChanged", nil); err != nil {
+		h.Log.Printf("error triggering contactsChanged: %v", err)
+	}
+	if err := htmx.Trigger(w, "showToast", map[string]string{"level": level, "message": message}); err != nil {
+		h.Log.Printf("error triggering showToast: %v", err)
+	}
+}
+
+// SessionCookieName names the cookie of a browser session, set by the pages.
+const SessionCookieName = "sessionID"
+
 // handleCookieSession handles session management using cookies.
 func (h *DefaultHandler) handleCookieSession(w http.ResponseWriter, r *http.Request) error {
-	cookieName := "sessionID"
+	cookieName := SessionCookieName
 
 	_, err := r.Cookie(cookieName)
 	if err == http.ErrNoCookie {
 
 		newCookieValue, err := internal.GenRandStr(32)
 		if err != nil {
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return err
 		}
 
@@ -358,10 +578,17 @@ func (h *DefaultHandler) handleCookieSession(w http.ResponseWriter, r *http.Requ
 			Value:    newCookieValue,
 			Expires:  time.Now().Add(time.Second * 6000),
 			HttpOnly: true,
+			Secure:   r.TLS != nil, // Never sent back over plain HTTP once on HTTPS.
+			SameSite: http.SameSiteLaxMode,
 		}
 
 		http.SetCookie(w, &newCookie)
-		h.ContactService.ResetContacts()
+		h.sessions.Add(1)
+		// A demo roster, fresh for each session, unless the roster is kept
+		// in a data file or snapshots, which a reset would wipe.
+		if h.Config.DataFile == "" && h.Config.SnapshotDir == "" {
+			h.ContactService.ResetContacts()
+		}
 		return nil
 	}
 
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/services"
+)
+
+func newTestHandler(contacts ...models.Contact) *DefaultHandler {
+	cfg := internal.DefaultConfig()
+	cs := services.NewContactService(cfg)
+	cs.Import(contacts)
+	return &DefaultHandler{Log: log.New(io.Discard, "", 0), ContactService: cs, Config: cfg}
+}
+
+func TestHandleUpdateContact(t *testing.T) {
+	contact := models.Contact{ID: uuid.New(), Name: "Ada Lovelace", Email: "ada@example.com", Status: models.StatusRegistered}
+
+	tests := []struct {
+		name       string
+		status     models.Status
+		wantStatus int
+		wantSaved  models.Status
+	}{
+		{"allowed transition", models.StatusCheckedIn, http.StatusOK, models.StatusCheckedIn},
+		{"disallowed transition", models.StatusCheckedOut, http.StatusUnprocessableEntity, models.StatusRegistered},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHandler(contact)
+			form := url.Values{
+				"id":     {contact.ID.String()},
+				"name":   {contact.Name},
+				"email":  {contact.Email},
+				"phone":  {"+1 202 555 0123"},
+				"status": {test.status.String()},
+				"tags":   {"R&D"},
+			}
+			r := httptest.NewRequest(http.MethodPut, "/contacts/"+contact.ID.String(), strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("HX-Request", "true")
+			w := httptest.NewRecorder()
+
+			h.HandleErrors(h.HandleUpdateContact).ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d: %s", w.Code, test.wantStatus, w.Body)
+			}
+			saved, _ := h.ContactService.Get()
+			if saved[0].Status != test.wantSaved {
+				t.Errorf("got saved status %s, want %s", saved[0].Status, test.wantSaved)
+			}
+		})
+	}
+}
+
+func TestParseContactFromRequestForm(t *testing.T) {
+	h := newTestHandler()
+	form := url.Values{"id": {uuid.NewString()}, "name": {"Ada"}, "email": {"ada@example.com"}, "phone": {"+1 202 555 0123"}, "tags": {"R&D, vip"}}
+	r := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	contact, errs := h.parseContactFromRequestForm(r)
+	if errs == nil || errs.Any() {
+		t.Fatalf("got errors %v, want an empty non-nil map", errs)
+	}
+	if got := strings.Join(contact.Tags, ","); got != "R&D,vip" {
+		t.Errorf("got tags %q, want %q", got, "R&D,vip")
+	}
+}
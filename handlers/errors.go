@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/internal/htmx"
+	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates/components"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandlerFunc is a handler that returns its failure instead of writing an
+// error response itself. Adapt it with DefaultHandler.HandleErrors.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// HTTPError is an error carrying the HTTP status it is reported with.
+type HTTPError struct {
+	Status  int
+	Message string // Shown to the user for 4xx statuses.
+	Err     error  // Optional cause, logged but never shown.
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+var (
+	ErrNotFound error = &HTTPError{Status: http.StatusNotFound, Message: "resource not found"}
+)
+
+// NewHTTPError returns an HTTPError with status and a formatted message.
+func NewHTTPError(status int, format string, a ...any) *HTTPError {
+	return &HTTPError{Status: status, Message: fmt.Sprintf(format, a...)}
+}
+
+// errorStatus maps err to the HTTP status it is reported with and the
+// message shown to the user. Unknown errors are internal server errors, and
+// their details stay out of the response.
+func errorStatus(err error) (status int, message string) {
+	var (
+		httpErr     *HTTPError
+		maxBytesErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &httpErr):
+		status, message = httpErr.Status, httpErr.Message
+	case errors.Is(err, internal.ErrCSRF):
+		status, message = http.StatusForbidden, "request blocked: reload the page and try again"
+	case errors.Is(err, internal.ErrRateLimited):
+		status, message = http.StatusTooManyRequests, "too many requests: wait a moment and try again"
+	case errors.As(err, &maxBytesErr):
+		status, message = http.StatusRequestEntityTooLarge, err.Error()
+	case errors.Is(err, services.ErrMergeNotFound), errors.Is(err, services.ErrFieldNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrFieldExists):
+		status, message = http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrMergeTooFew),
+		errors.Is(err, services.ErrMergeBadKeeper),
+		errors.Is(err, services.ErrInvalidTransition):
+		status, message = http.StatusUnprocessableEntity, err.Error()
+	default:
+		status = http.StatusInternalServerError
+	}
+
+	if status >= http.StatusInternalServerError || message == "" {
+		message = http.StatusText(status)
+	}
+	return status, message
+}
+
+// HandleErrors adapts fn to an http.Handler that reports the error fn
+// returns via RenderError. Errors returned after fn started writing the
+// response can only be logged.
+func (h *DefaultHandler) HandleErrors(fn HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+
+		if err := fn(sw, r); err != nil {
+			if sw.wroteHeader {
+				h.Log.Printf("error after response started: %s %s: %v", r.Method, r.URL.Path, err)
+				return
+			}
+			h.RenderError(w, r, err)
+		}
+	})
+}
+
+// HandleNotFound handles every route that matches no other pattern.
+func (h *DefaultHandler) HandleNotFound(w http.ResponseWriter, r *http.Request) error { // 404
+	return ErrNotFound
+}
+
+// RenderError writes err in the shape the client expects:
+//
+//   - JSON for API requests, i.e. paths under "/api/".
+//   - A toast appended to "#toast-container" for htmx requests of partials.
+//   - NotFoundPage, ServerErrorPage or ErrorPage otherwise, rendered like
+//     any other page with renderPage.
+func (h *DefaultHandler) RenderError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := errorStatus(err)
+	h.Log.Printf("%s %s: %d: %v", r.Method, r.URL.Path, status, err)
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = h.renderJSON(w, map[string]string{"error": message})
+
+	case htmx.IsPartial(r):
+		htmx.Retarget(w, "#toast-container")
+		htmx.Reswap(w, "beforeend")
+		w.WriteHeader(status)
+		_ = h.renderView(w, r, components.ErrorToast(status, message))
+
+	case status == http.StatusNotFound:
+		_ = h.renderPage(w, r, status, "Not found", pages.NotFoundPage())
+
+	case status >= http.StatusInternalServerError:
+		_ = h.renderPage(w, r, status, "Error", pages.ServerErrorPage())
+
+	default:
+		_ = h.renderPage(w, r, status, http.StatusText(status), pages.ErrorPage(status, message))
+	}
+}
+
+// statusWriter records whether the response has started.
+type statusWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/services"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"http error", NewHTTPError(http.StatusBadRequest, "invalid id %q", "x"), http.StatusBadRequest, `invalid id "x"`},
+		{"wrapped http error", fmt.Errorf("handler: %w", ErrNotFound), http.StatusNotFound, "resource not found"},
+		{"csrf", internal.ErrCSRF, http.StatusForbidden, "request blocked: reload the page and try again"},
+		{"rate limited", internal.ErrRateLimited, http.StatusTooManyRequests, "too many requests: wait a moment and try again"},
+		{"too large", &http.MaxBytesError{Limit: 10}, http.StatusRequestEntityTooLarge, "http: request body too large"},
+		{"field exists", services.ErrFieldExists, http.StatusConflict, services.ErrFieldExists.Error()},
+		{"invalid transition", services.ErrInvalidTransition, http.StatusUnprocessableEntity, services.ErrInvalidTransition.Error()},
+		{"server error with message", &HTTPError{Status: http.StatusServiceUnavailable, Message: "secret"}, http.StatusServiceUnavailable, "Service Unavailable"},
+		{"unknown", errors.New("database password is hunter2"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := errorStatus(test.err)
+			if status != test.wantStatus || message != test.wantMessage {
+				t.Errorf("got %d %q, want %d %q", status, message, test.wantStatus, test.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHandleErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		fn         HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "api error as json",
+			path: "/api/contacts",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				return NewHTTPError(http.StatusBadRequest, "bad filter")
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"bad filter"}`,
+		},
+		{
+			name:       "internal error hidden",
+			path:       "/api/contacts",
+			fn:         func(w http.ResponseWriter, r *http.Request) error { return errors.New("hunter2") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error"}`,
+		},
+		{
+			name: "error after response started",
+			path: "/api/contacts",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, "partial")
+				return errors.New("write failed")
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "partial",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHandler()
+			w := httptest.NewRecorder()
+			h.HandleErrors(test.fn).ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			if w.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, test.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != test.wantBody {
+				t.Errorf("got body %q, want %q", got, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates/components"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandleFieldsPage handles HTTP GET - /admin/fields.
+func (h *DefaultHandler) HandleFieldsPage(w http.ResponseWriter, r *http.Request) error {
+	html := pages.FieldsPage(h.ContactService.Fields())
+	return h.renderPage(w, r, http.StatusOK, "Fields", html)
+}
+
+// HandleDefineField handles HTTP POST - /admin/fields.
+//
+// Options of a select field are read as a comma separated list.
+func (h *DefaultHandler) HandleDefineField(w http.ResponseWriter, r *http.Request) error {
+	fd := models.FieldDefinition{
+		Key:      strings.TrimSpace(r.FormValue("key")),
+		Label:    strings.TrimSpace(r.FormValue("label")),
+		Type:     models.FieldType(r.FormValue("type")),
+		Required: r.FormValue("required") == "on",
+	}
+
+	for _, option := range strings.Split(r.FormValue("options"), ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			fd.Options = append(fd.Options, option)
+		}
+	}
+
+	if err := h.ContactService.DefineField(fd); err != nil {
+		errs := models.FieldErrors{}
+		switch {
+		case errors.Is(err, services.ErrFieldExists), errors.Is(err, models.ErrFieldKey):
+			errs.Add("key", err.Error())
+		case errors.Is(err, models.ErrFieldLabel):
+			errs.Add("label", err.Error())
+		case errors.Is(err, models.ErrFieldType):
+			errs.Add("type", err.Error())
+		case errors.Is(err, models.ErrFieldOptions):
+			errs.Add("options", err.Error())
+		default:
+			return err
+		}
+
+		return h.renderFormErrors(w, r, "#fields-admin", components.FieldsAdmin(h.ContactService.Fields(), errs))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	html := components.FieldsAdmin(h.ContactService.Fields(), nil)
+	return h.renderView(w, r, html)
+}
+
+// HandleRemoveField handles HTTP DELETE - /admin/fields/{key}.
+func (h *DefaultHandler) HandleRemoveField(w http.ResponseWriter, r *http.Request) error {
+	if err := h.ContactService.RemoveField(r.PathValue("key")); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	html := components.FieldsAdmin(h.ContactService.Fields(), nil)
+	return h.renderView(w, r, html)
+}
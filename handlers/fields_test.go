@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandleDefineField(t *testing.T) {
+	tests := []struct {
+		name      string
+		form      url.Values
+		wantError string // Form field showing the error.
+	}{
+		{"bad key", url.Values{"key": {"T-shirt"}, "label": {"T-shirt"}, "type": {"text"}}, "key"},
+		{"missing label", url.Values{"key": {"company"}, "type": {"text"}}, "label"},
+		{"unknown type", url.Values{"key": {"company"}, "label": {"Company"}, "type": {"color"}}, "type"},
+		{"select without options", url.Values{"key": {"size"}, "label": {"Size"}, "type": {"select"}}, "options"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHandler()
+			r := httptest.NewRequest(http.MethodPost, "/admin/fields", strings.NewReader(test.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("HX-Request", "true")
+			w := httptest.NewRecorder()
+
+			h.HandleErrors(h.HandleDefineField).ServeHTTP(w, r)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("got status %d, want 422", w.Code)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, `id="`+test.wantError+`-error"`) || strings.Count(body, `role="alert"`) != 1 {
+				t.Errorf("got errors in %s, want them under %q only", body, test.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// maxCSPReportSize caps the size of a Content-Security-Policy violation report.
+const maxCSPReportSize = 64 << 10 // 64 KiB
+
+// cspReport is a violation report browsers post to the policy's report-uri.
+type cspReport struct {
+	Body struct {
+		DocumentURI        string `json:"document-uri"`
+		EffectiveDirective string `json:"effective-directive"`
+		ViolatedDirective  string `json:"violated-directive"`
+		BlockedURI         string `json:"blocked-uri"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		Disposition        string `json:"disposition"` // "enforce" or "report"
+	} `json:"csp-report"`
+}
+
+// HandleCSPReport handles HTTP POST - /csp-report.
+//
+// Logs Content-Security-Policy violations reported by browsers, see
+// internal.SecureHeaders. Responds with 204 No Content.
+func (h *DefaultHandler) HandleCSPReport(w http.ResponseWriter, r *http.Request) error {
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCSPReportSize))
+	if err != nil {
+		return err
+	}
+
+	var report cspReport
+	if err := json.Unmarshal(b, &report); err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid csp report: %v", err)
+	}
+
+	v := report.Body
+	directive := v.EffectiveDirective
+	if directive == "" {
+		directive = v.ViolatedDirective
+	}
+	h.Log.Printf("csp violation (%s): %s blocked %q on %s at %s:%d", v.Disposition, directive, v.BlockedURI, v.DocumentURI, v.SourceFile, v.LineNumber)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
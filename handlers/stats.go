@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandleStatsPage handles HTTP GET - /stats.
+func (h *DefaultHandler) HandleStatsPage(w http.ResponseWriter, r *http.Request) error {
+	counts := make(map[models.Status]int, len(models.Statuses))
+	for _, status := range models.Statuses {
+		counts[status] = h.ContactService.CountByStatus(status)
+	}
+
+	html := pages.StatsPage(h.Config.EventName, h.ContactService.Count(), counts)
+	return h.renderPage(w, r, http.StatusOK, "Stats", html)
+}
+
+// HandleAttendanceReport handles HTTP GET - /stats/report.pdf.
+//
+// Downloads a printable attendance report of every contact, headed by
+// Config.EventName, see services.WriteAttendanceReport.
+func (h *DefaultHandler) HandleAttendanceReport(w http.ResponseWriter, r *http.Request) error {
+	contacts, err := h.ContactService.Get()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filename := fmt.Sprintf("attendance-%s.pdf", now.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	info := services.ReportInfo{EventName: h.Config.EventName, BuildTag: h.BuildTag, Generated: now}
+	if err := services.WriteAttendanceReport(w, contacts, info); err != nil {
+		h.Log.Printf("error writing attendance report: %v", err)
+	}
+	return nil
+}
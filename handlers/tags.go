@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/templates/components"
+	"github.com/lloydlobo/go-headcount/templates/pages"
+)
+
+// HandleTagsPage handles HTTP GET - /tags.
+func (h *DefaultHandler) HandleTagsPage(w http.ResponseWriter, r *http.Request) error {
+	html := pages.TagsPage(h.ContactService.Tags())
+	return h.renderPage(w, r, http.StatusOK, "Tags", html)
+}
+
+// HandleBulkTag handles HTTP POST - /contacts/tags.
+//
+// Adds or removes "bulk_tag" on the contacts checked in "ids", depending on
+// "action" being "tag" or "untag". Responds with the ContactsTable filtered by
+// "tag", the value of components.TagFilter.
+func (h *DefaultHandler) HandleBulkTag(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid form: %v", err)
+	}
+
+	tag := models.NormalizeTag(r.PostForm.Get("bulk_tag"))
+	if tag == "" {
+		return NewHTTPError(http.StatusBadRequest, "tag is required")
+	}
+
+	ids := make([]uuid.UUID, 0, len(r.PostForm["ids"]))
+	for _, id := range r.PostForm["ids"] {
+		uuidID, err := uuid.Parse(id)
+		if err != nil {
+			return NewHTTPError(http.StatusBadRequest, "invalid contact id: %v", err)
+		}
+		ids = append(ids, uuidID)
+	}
+
+	switch r.PostForm.Get("action") {
+	case "tag":
+		h.ContactService.TagContacts(ids, tag)
+	case "untag":
+		h.ContactService.UntagContacts(ids, tag)
+	default:
+		return NewHTTPError(http.StatusBadRequest, "invalid action: use either 'tag' or 'untag'")
+	}
+
+	filter := models.ContactFilter{Tag: r.PostForm.Get("tag")}
+
+	w.WriteHeader(http.StatusOK)
+	html := components.ContactsTable(h.ContactService.Filter(filter), h.ContactService.Fields())
+	return h.renderView(w, r, html)
+}
+
+// HandleRenameTag handles HTTP PUT - /tags/{tag}.
+func (h *DefaultHandler) HandleRenameTag(w http.ResponseWriter, r *http.Request) error {
+	newTag := models.NormalizeTag(r.FormValue("name"))
+	if newTag == "" {
+		return NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+
+	h.ContactService.RenameTag(r.PathValue("tag"), newTag)
+
+	w.WriteHeader(http.StatusOK)
+	html := components.TagsTable(h.ContactService.Tags())
+	return h.renderView(w, r, html)
+}
+
+// HandleDeleteTag handles HTTP DELETE - /tags/{tag}.
+func (h *DefaultHandler) HandleDeleteTag(w http.ResponseWriter, r *http.Request) error {
+	h.ContactService.DeleteTag(r.PathValue("tag"))
+
+	w.WriteHeader(http.StatusOK)
+	html := components.TagsTable(h.ContactService.Tags())
+	return h.renderView(w, r, html)
+}
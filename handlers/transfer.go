@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/internal/htmx"
+	"github.com/lloydlobo/go-headcount/internal/xlsx"
+	"github.com/lloydlobo/go-headcount/models"
+	"github.com/lloydlobo/go-headcount/services"
+	"github.com/lloydlobo/go-headcount/templates/components"
+)
+
+// maxImportSize caps the size of uploaded roster files.
+const maxImportSize = 10 << 20 // 10 MiB
+
+// HandleExportCSV handles HTTP GET - /contacts/export.csv.
+func (h *DefaultHandler) HandleExportCSV(w http.ResponseWriter, r *http.Request) error {
+	contacts, err := h.ContactService.Get()
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("contacts-%s.csv", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := services.WriteCSV(w, contacts, h.ContactService.Fields()); err != nil {
+		h.Log.Printf("error writing csv export: %v", err)
+	}
+	return nil
+}
+
+// HandleExportVCard handles HTTP GET - /contacts/export.vcf.
+//
+// Exports the contacts of the "ids" query parameters, or every contact
+// without any, as vCards. htmx requests, e.g. from BulkTagActions, are
+// redirected to the same export, so that the browser downloads it.
+func (h *DefaultHandler) HandleExportVCard(w http.ResponseWriter, r *http.Request) error {
+	ids := r.URL.Query()["ids"]
+	if htmx.IsRequest(r) {
+		if len(ids) == 0 {
+			return NewHTTPError(http.StatusBadRequest, "check the contacts to export")
+		}
+		htmx.Redirect(w, "/contacts/export.vcf?"+url.Values{"ids": ids}.Encode())
+		return nil
+	}
+
+	contacts, err := h.ContactService.Get()
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		contacts = slices.DeleteFunc(slices.Clone(contacts), func(c models.Contact) bool {
+			return !slices.Contains(ids, c.ID.String())
+		})
+	}
+
+	filename := fmt.Sprintf("contacts-%s.vcf", time.Now().Format("20060102-150405"))
+	return h.renderVCard(w, contacts, filename)
+}
+
+// HandleExportXLSX handles HTTP GET - /contacts/export.xlsx.
+//
+// Exports the contacts matching the tag and status filters of
+// HandleGetContactsCount as a workbook of the roster, a summary per status
+// and the attendance timeline. htmx requests, e.g. from the index toolbar
+// including the tag filter, are redirected to the same export, so that the
+// browser downloads it.
+func (h *DefaultHandler) HandleExportXLSX(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseContactFilter(r.URL.Query())
+	if err != nil {
+		return err
+	}
+	if htmx.IsRequest(r) {
+		htmx.Redirect(w, "/contacts/export.xlsx?"+r.URL.RawQuery)
+		return nil
+	}
+
+	contacts := h.ContactService.Filter(filter)
+
+	filename := fmt.Sprintf("contacts-%s.xlsx", time.Now().Format("20060102-150405"))
+	w.Header().Set("Content-Type", xlsx.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := services.WriteXLSX(w, contacts, h.ContactService.Fields()); err != nil {
+		h.Log.Printf("error writing xlsx export: %v", err)
+	}
+	return nil
+}
+
+// renderVCard writes contacts as a vCard attachment named filename.
+func (h *DefaultHandler) renderVCard(w http.ResponseWriter, contacts models.Contacts, filename string) error {
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := services.WriteVCard(w, contacts); err != nil {
+		h.Log.Printf("error writing vcard export: %v", err)
+	}
+	return nil
+}
+
+// HandleImportContacts handles HTTP POST - /contacts/import.
+//
+// Expects a multipart form with a CSV or vCard "file", told apart by its
+// extension. The import is all or nothing: if any row or card is invalid
+// nothing is imported and every invalid one is reported. Responds with the
+// updated ContactsTable.
+func (h *DefaultHandler) HandleImportContacts(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return NewHTTPError(http.StatusBadRequest, "error reading uploaded file: %v", err)
+	}
+	defer file.Close()
+
+	read := services.ReadCSV
+	switch strings.ToLower(path.Ext(header.Filename)) {
+	case ".vcf", ".vcard":
+		read = services.ReadVCard
+	}
+
+	contacts, err := read(file, h.ContactService.Fields(), h.Config.PhoneRegion)
+	if err != nil {
+		h.Log.Printf("error importing %s: %v", header.Filename, err)
+		return h.renderFormErrors(w, r, "#import-errors", components.ImportErrors(strings.Split(err.Error(), "\n"), false))
+	}
+
+	added, updated := h.ContactService.Import(contacts)
+
+	all, err := h.ContactService.Get()
+	if err != nil {
+		return err
+	}
+
+	h.triggerContactsChanged(w, toastOK, fmt.Sprintf("Imported %d contacts, updated %d", added, updated))
+	w.WriteHeader(http.StatusOK)
+	if err := h.renderView(w, r, components.ContactsTable(all, h.ContactService.Fields())); err != nil {
+		return err
+	}
+	return h.renderView(w, r, components.ImportErrors(nil, true)) // Clears errors of a previous attempt.
+}
+
+// HandleAPIContacts handles HTTP GET - /api/contacts.
+//
+// Responds with every contact as JSON, custom field values under "custom".
+func (h *DefaultHandler) HandleAPIContacts(w http.ResponseWriter, r *http.Request) error {
+	contacts, err := h.ContactService.Get()
+	if err != nil {
+		return err
+	}
+
+	return h.renderJSON(w, contacts)
+}
+
+// HandleAPIFields handles HTTP GET - /api/fields.
+//
+// Responds with the custom field definitions as JSON.
+func (h *DefaultHandler) HandleAPIFields(w http.ResponseWriter, r *http.Request) error {
+	return h.renderJSON(w, h.ContactService.Fields())
+}
+
+// renderJSON encodes v to http.ResponseWriter with application/json content type.
+func (h *DefaultHandler) renderJSON(w http.ResponseWriter, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,191 @@
+// Package assets serves static files under content-hashed URLs.
+//
+// A Manifest hashes every file of a file system once, at startup, and maps
+// each logical path such as "js/htmx.min.js" to a URL carrying the hash,
+// e.g. "/static/js/htmx.3f2a9c1e.min.js". A file's URL changes whenever its
+// content does, so hashed URLs can be cached by browsers forever.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/lloydlobo/go-headcount/internal"
+)
+
+const (
+	hashLength = 8
+
+	// CacheImmutable is sent for hashed URLs.
+	CacheImmutable = "public, max-age=31536000, immutable"
+	// CacheRevalidate is sent for files requested by their plain path.
+	CacheRevalidate = "no-cache"
+)
+
+// Manifest maps the logical paths of a file system to hashed URLs.
+type Manifest struct {
+	fsys    fs.FS
+	prefix  string
+	live    bool
+	sums    map[string]string // Logical path to content digest.
+	hashed  map[string]string // Logical path to hashed path.
+	logical map[string]string // Hashed path to logical path.
+}
+
+// New hashes every file of fsys. URLs are rooted at prefix, e.g. "/static/".
+func New(fsys fs.FS, prefix string) (*Manifest, error) {
+	m := newManifest(fsys, prefix)
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := digest(b)
+		hashedName := HashedName(name, sum[:hashLength])
+
+		m.sums[name] = sum
+		m.hashed[name] = hashedName
+		m.logical[hashedName] = name
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error hashing assets: %w", err)
+	}
+	return m, nil
+}
+
+// Live returns a manifest of plain URLs reading fsys on every request, e.g.
+// the static directory on disk, so edits show up on reload without a
+// rebuild. Responses must always be revalidated.
+func Live(fsys fs.FS, prefix string) *Manifest {
+	m := newManifest(fsys, prefix)
+	m.live = true
+	return m
+}
+
+func newManifest(fsys fs.FS, prefix string) *Manifest {
+	return &Manifest{
+		fsys:    fsys,
+		prefix:  "/" + strings.Trim(prefix, "/") + "/",
+		sums:    make(map[string]string),
+		hashed:  make(map[string]string),
+		logical: make(map[string]string),
+	}
+}
+
+// digest returns the hex content digest used for hashed names and ETags.
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
+
+// HashedName inserts hash into name before its extension, keeping a ".min"
+// suffix with it: "js/htmx.min.js" becomes "js/htmx.<hash>.min.js".
+func HashedName(name, hash string) string {
+	dir, base := path.Split(name)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		return dir + base + "." + hash
+	}
+	if strings.HasSuffix(stem, ".min") {
+		stem, ext = strings.TrimSuffix(stem, ".min"), ".min"+ext
+	}
+	return dir + stem + "." + hash + ext
+}
+
+// Path returns the URL of the asset at the logical path name: its hashed
+// URL, or its plain URL if the manifest does not know it, as for every file
+// of a Live manifest.
+func (m *Manifest) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashedName, ok := m.hashed[name]; ok {
+		return m.prefix + hashedName
+	}
+	return m.prefix + name
+}
+
+// Handler serves the files of the manifest under its prefix. Hashed URLs
+// are cached immutably; plain paths still work but must be revalidated.
+func (m *Manifest) Handler() http.Handler {
+	return http.StripPrefix(strings.TrimSuffix(m.prefix, "/"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if logical, ok := m.logical[name]; ok {
+			w.Header().Set("Cache-Control", CacheImmutable)
+			m.ServeFile(w, r, logical)
+			return
+		}
+
+		w.Header().Set("Cache-Control", CacheRevalidate)
+		m.ServeFile(w, r, name)
+	}))
+}
+
+// ServeFile replies with the file at the logical path name. Besides
+// conditional requests with If-None-Match, via the file's ETag, it supports
+// range requests, see http.ServeContent.
+//
+// A precompressed copy at name+".gz", e.g. made with `make precompress`, is
+// sent instead to clients accepting gzip, except for range requests.
+// Directories are not listed: they are not found, like missing files.
+func (m *Manifest) ServeFile(w http.ResponseWriter, r *http.Request, name string) {
+	if !m.has(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if gz := name + ".gz"; m.has(gz) {
+		internal.AddVary(w.Header(), "Accept-Encoding")
+		if r.Header.Get("Range") == "" && internal.NegotiateEncoding(r.Header.Get("Accept-Encoding"), "gzip") != "" {
+			if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+				w.Header().Set("Content-Type", ctype)
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			name = gz
+		}
+	}
+
+	if etag := m.etag(name); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	http.ServeFileFS(w, r, m.fsys, name)
+}
+
+// has reports whether the file at name exists.
+func (m *Manifest) has(name string) bool {
+	if !m.live {
+		_, ok := m.sums[name]
+		return ok
+	}
+	info, err := fs.Stat(m.fsys, name)
+	return err == nil && !info.IsDir()
+}
+
+// etag returns the strong ETag of the file at name, or "" for directories
+// and missing files.
+func (m *Manifest) etag(name string) string {
+	if !m.live {
+		if sum, ok := m.sums[name]; ok {
+			return `"` + sum + `"`
+		}
+		return ""
+	}
+
+	b, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return ""
+	}
+	return `"` + digest(b) + `"`
+}
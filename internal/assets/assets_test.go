@@ -0,0 +1,193 @@
+package assets
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestManifest(t *testing.T) *Manifest {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"js/htmx.min.js": {Data: []byte("htmx")},
+		"css/style.css":  {Data: []byte("body {}")},
+	}
+	m, err := New(fsys, "/static/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestHashedName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"js/htmx.min.js", "js/htmx.abcd.min.js"},
+		{"css/style.css", "css/style.abcd.css"},
+		{"js/alpinejs@3.x.x.min.js", "js/alpinejs@3.x.x.abcd.min.js"},
+		{"js/htmx.swap-errors.js", "js/htmx.swap-errors.abcd.js"},
+		{"robots", "robots.abcd"},
+		{"hs/.hidden", "hs/.hidden.abcd"},
+	}
+
+	for _, test := range tests {
+		if got := HashedName(test.name, "abcd"); got != test.want {
+			t.Errorf("HashedName(%q): got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestManifestPath(t *testing.T) {
+	m := newTestManifest(t)
+
+	if got := m.Path("js/htmx.min.js"); !regexp.MustCompile(`^/static/js/htmx\.[0-9a-f]{8}\.min\.js$`).MatchString(got) {
+		t.Errorf("got %q, want a hashed URL", got)
+	}
+	if got, want := m.Path("img/unknown.png"), "/static/img/unknown.png"; got != want {
+		t.Errorf("unknown file: got %q, want %q", got, want)
+	}
+}
+
+func TestManifestHandler(t *testing.T) {
+	m := newTestManifest(t)
+
+	tests := []struct {
+		name, path  string
+		wantStatus  int
+		wantCaching string
+	}{
+		{"Hashed URL", m.Path("js/htmx.min.js"), 200, CacheImmutable},
+		{"Plain path", "/static/js/htmx.min.js", 200, CacheRevalidate},
+		{"Stale hash", "/static/js/htmx.00000000.min.js", 404, CacheRevalidate},
+		{"Root directory", "/static/", 404, CacheRevalidate},
+		{"Directory", "/static/js/", 404, CacheRevalidate},
+		{"Directory without slash", "/static/js", 404, CacheRevalidate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+			if w.Code != test.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, test.wantStatus)
+			}
+			if got := w.Header().Get("Cache-Control"); got != test.wantCaching {
+				t.Errorf("Cache-Control: got %q, want %q", got, test.wantCaching)
+			}
+			if test.wantStatus == 200 && w.Body.String() != "htmx" {
+				t.Errorf("body: got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestManifestConditionalAndRange(t *testing.T) {
+	m := newTestManifest(t)
+	url := m.Path("js/htmx.min.js")
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	r := httptest.NewRequest("GET", url, nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, r)
+	if w.Code != 304 {
+		t.Errorf("If-None-Match: got status %d, want 304", w.Code)
+	}
+
+	r = httptest.NewRequest("GET", url, nil)
+	r.Header.Set("Range", "bytes=1-2")
+	w = httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, r)
+	if w.Code != 206 || w.Body.String() != "tm" {
+		t.Errorf("Range: got status %d and body %q, want 206 and %q", w.Code, w.Body.String(), "tm")
+	}
+}
+
+func TestLive(t *testing.T) {
+	fsys := fstest.MapFS{"js/app.js": {Data: []byte("v1")}}
+	m := Live(fsys, "/static")
+
+	if got, want := m.Path("js/app.js"), "/static/js/app.js"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/static/js/app.js", nil))
+		return w
+	}
+
+	before := serve()
+	fsys["js/app.js"] = &fstest.MapFile{Data: []byte("v2")}
+	after := serve()
+
+	if after.Body.String() != "v2" {
+		t.Errorf("got body %q, want the edited file", after.Body.String())
+	}
+	if before.Header().Get("ETag") == after.Header().Get("ETag") {
+		t.Error("ETag did not change with the file")
+	}
+	if got := after.Header().Get("Cache-Control"); got != CacheRevalidate {
+		t.Errorf("Cache-Control: got %q, want %q", got, CacheRevalidate)
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/static/js/", nil))
+	if w.Code != 404 {
+		t.Errorf("directory: got status %d, want 404", w.Code)
+	}
+}
+
+func TestServePrecompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"js/app.js":    {Data: []byte("plain")},
+		"js/app.js.gz": {Data: []byte("gzipped")},
+	}
+	m, err := New(fsys, "/static/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, accept, rangeHeader string
+		wantBody, wantEncoding    string
+	}{
+		{"Accepts gzip", "gzip, deflate", "", "gzipped", "gzip"},
+		{"Refuses gzip", "gzip;q=0", "", "plain", ""},
+		{"Range request", "gzip", "bytes=0-1", "pl", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", m.Path("js/app.js"), nil)
+			r.Header.Set("Accept-Encoding", test.accept)
+			if test.rangeHeader != "" {
+				r.Header.Set("Range", test.rangeHeader)
+			}
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, r)
+
+			if w.Body.String() != test.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), test.wantBody)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != test.wantEncoding {
+				t.Errorf("Content-Encoding: got %q, want %q", got, test.wantEncoding)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+				t.Errorf("Content-Type: got %q", got)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary: got %q, want Accept-Encoding", got)
+			}
+		})
+	}
+}
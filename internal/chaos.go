@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosAnyRoute is the pattern of a ChaosRule applying to every route
+// without a rule of its own.
+const ChaosAnyRoute = "*"
+
+// ChaosRule injects faults into the requests of a route, e.g. to watch
+// htmx loading indicators, hx-confirm flows and retries during development.
+//
+// The rates are shares of requests, from 0 to 1, adding up to at most 1.
+type ChaosRule struct {
+	Pattern         string   `json:"pattern"`         // ServeMux pattern of the route, e.g. "POST /contacts", or ChaosAnyRoute.
+	Latency         Duration `json:"latency"`         // Delay before every response.
+	ErrorRate       float64  `json:"errorRate"`       // Requests answered 500 Internal Server Error.
+	UnavailableRate float64  `json:"unavailableRate"` // Requests answered 503 Service Unavailable.
+	DropRate        float64  `json:"dropRate"`        // Requests whose connection is closed without a response.
+}
+
+// Check reports whether r is a valid rule.
+func (r ChaosRule) Check() error {
+	var errs []error
+	if strings.TrimSpace(r.Pattern) == "" {
+		errs = append(errs, errors.New("pattern: required, e.g. \"POST /contacts\" or \"*\""))
+	}
+	if r.Latency < 0 {
+		errs = append(errs, fmt.Errorf("latency: must not be negative, got %s", time.Duration(r.Latency)))
+	}
+	for _, rate := range []struct {
+		name  string
+		value float64
+	}{{"errorRate", r.ErrorRate}, {"unavailableRate", r.UnavailableRate}, {"dropRate", r.DropRate}} {
+		if rate.value < 0 || rate.value > 1 {
+			errs = append(errs, fmt.Errorf("%s: want a share from 0 to 1, got %v", rate.name, rate.value))
+		}
+	}
+	if r.ErrorRate+r.UnavailableRate+r.DropRate > 1 {
+		errs = append(errs, errors.New("rates: must add up to at most 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// Chaos holds the ChaosRule of each route, changeable while serving.
+type Chaos struct {
+	mu    sync.Mutex
+	rules map[string]ChaosRule
+
+	random func() float64 // Defaults to rand.Float64.
+}
+
+// NewChaos returns a Chaos injecting rules.
+//
+// Usage
+//
+//	chaos, err := internal.NewChaos([]internal.ChaosRule{{Pattern: "POST /contacts", Latency: internal.Duration(2 * time.Second)}})
+//	handler := chaos.Middleware(mux)(mux)
+func NewChaos(rules []ChaosRule) (*Chaos, error) {
+	c := &Chaos{rules: make(map[string]ChaosRule), random: rand.Float64}
+	for _, rule := range rules {
+		if err := c.SetRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Rules returns the rules, sorted by pattern.
+func (c *Chaos) Rules() []ChaosRule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rules := make([]ChaosRule, 0, len(c.rules))
+	for _, rule := range c.rules {
+		rules = append(rules, rule)
+	}
+	slices.SortFunc(rules, func(a, b ChaosRule) int { return strings.Compare(a.Pattern, b.Pattern) })
+	return rules
+}
+
+// SetRule adds rule, replacing the rule of the same pattern.
+func (c *Chaos) SetRule(rule ChaosRule) error {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if err := rule.Check(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules[rule.Pattern] = rule
+	return nil
+}
+
+// RemoveRule removes the rule of pattern, if any.
+func (c *Chaos) RemoveRule(pattern string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rules, pattern)
+}
+
+func (c *Chaos) rule(pattern string) (ChaosRule, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rule, ok := c.rules[pattern]; ok {
+		return rule, true
+	}
+	rule, ok := c.rules[ChaosAnyRoute]
+	return rule, ok
+}
+
+// Middleware returns middleware injecting the rule of the route of each
+// request, found by its pattern in mux. Routes of the exempt patterns, e.g.
+// the page editing the rules, are left alone.
+//
+// Injected responses carry a Chaos header naming the fault.
+func (c *Chaos) Middleware(mux *http.ServeMux, exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+			rule, ok := c.rule(pattern)
+			if !ok || slices.Contains(exempt, pattern) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rule.Latency > 0 {
+				w.Header().Set("Chaos", "latency="+time.Duration(rule.Latency).String())
+				timer := time.NewTimer(time.Duration(rule.Latency))
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			switch p := c.random(); {
+			case p < rule.DropRate:
+				panic(http.ErrAbortHandler) // Closes the connection, or resets the HTTP/2 stream.
+			case p < rule.DropRate+rule.ErrorRate:
+				w.Header().Add("Chaos", "error")
+				http.Error(w, "chaos: injected internal server error", http.StatusInternalServerError)
+			case p < rule.DropRate+rule.ErrorRate+rule.UnavailableRate:
+				w.Header().Add("Chaos", "unavailable")
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "chaos: injected service unavailable", http.StatusServiceUnavailable)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
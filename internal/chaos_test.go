@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChaosRuleCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ChaosRule
+		ok   bool
+	}{
+		{"Latency only", ChaosRule{Pattern: "GET /{$}", Latency: Duration(time.Second)}, true},
+		{"Every route", ChaosRule{Pattern: ChaosAnyRoute, ErrorRate: 0.5, DropRate: 0.5}, true},
+		{"Missing pattern", ChaosRule{ErrorRate: 0.1}, false},
+		{"Rate above 1", ChaosRule{Pattern: "*", UnavailableRate: 1.5}, false},
+		{"Rates above 1", ChaosRule{Pattern: "*", ErrorRate: 0.6, DropRate: 0.6}, false},
+		{"Negative latency", ChaosRule{Pattern: "*", Latency: Duration(-time.Second)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.rule.Check(); (err == nil) != test.ok {
+				t.Errorf("got %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestChaosMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) }
+	mux.HandleFunc("POST /contacts", ok)
+	mux.HandleFunc("GET /contacts", ok)
+	mux.HandleFunc("GET /debug/chaos", ok)
+
+	chaos, err := NewChaos([]ChaosRule{
+		{Pattern: "POST /contacts", ErrorRate: 0.2, UnavailableRate: 0.3, DropRate: 0.1},
+		{Pattern: ChaosAnyRoute, Latency: Duration(time.Millisecond)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := chaos.Middleware(mux, "GET /debug/chaos")(mux)
+
+	serve := func(method, target string, p float64) (w *httptest.ResponseRecorder, dropped bool) {
+		chaos.random = func() float64 { return p }
+		w = httptest.NewRecorder()
+		defer func() {
+			if v := recover(); v != nil {
+				if err, _ := v.(error); !errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+				dropped = true
+			}
+		}()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w, false
+	}
+
+	if _, dropped := serve("POST", "/contacts", 0.05); !dropped {
+		t.Error("p 0.05: got a response, want a dropped connection")
+	}
+	if w, _ := serve("POST", "/contacts", 0.2); w.Code != http.StatusInternalServerError {
+		t.Errorf("p 0.2: got %d, want 500", w.Code)
+	}
+	if w, _ := serve("POST", "/contacts", 0.5); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("p 0.5: got %d, want 503 with Retry-After", w.Code)
+	}
+	if w, _ := serve("POST", "/contacts", 0.7); w.Code != http.StatusOK {
+		t.Errorf("p 0.7: got %d, want 200", w.Code)
+	}
+
+	// Other routes fall back to the rule of every route.
+	if w, _ := serve("GET", "/contacts", 0); w.Code != http.StatusOK || w.Header().Get("Chaos") != "latency=1ms" {
+		t.Errorf("GET /contacts: got %d with Chaos %q, want 200 with latency=1ms", w.Code, w.Header().Get("Chaos"))
+	}
+	if w, _ := serve("GET", "/debug/chaos", 0); w.Header().Get("Chaos") != "" {
+		t.Errorf("exempt route: got Chaos %q, want none", w.Header().Get("Chaos"))
+	}
+
+	chaos.RemoveRule(ChaosAnyRoute)
+	if w, _ := serve("GET", "/contacts", 0); w.Header().Get("Chaos") != "" {
+		t.Errorf("after RemoveRule: got Chaos %q, want none", w.Header().Get("Chaos"))
+	}
+}
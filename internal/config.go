@@ -1,17 +1,408 @@
 package internal
 
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the settings of the server. Load it with LoadConfig, which
+// layers, from lowest to highest precedence, DefaultConfig, a JSON config
+// file, environment variables and command-line flags.
 type Config struct {
-	ApiUrl         string
-	Debug          bool
-	DebugSleep     bool
-	DebugSleepSecs int
-	WithProfiling  bool
-}
-
-var ServerConfig = Config{
-	ApiUrl:         LookupEnv("API_URL", "https://jsonplaceholder.typicode.com/users"),
-	Debug:          true,
-	DebugSleep:     false,
-	DebugSleepSecs: 2,
-	WithProfiling:  false,
+	Port             string         `json:"port"`
+	ApiUrl           string         `json:"apiUrl"` // Seeds contacts at startup. Its password, if any, is a secret.
+	Debug            bool           `json:"debug"`
+	DebugSleep       bool           `json:"debugSleep"` // Delay every response by DebugSleepSecs, see Chaos.
+	DebugSleepSecs   int            `json:"debugSleepSecs"`
+	Chaos            bool           `json:"chaos"` // Inject the faults of ChaosRules, editable at /debug/chaos. For development only.
+	ChaosRules       []ChaosRule    `json:"chaosRules"`
+	WithProfiling    bool           `json:"withProfiling"`    // Serve pprof, expvar and /debug/routes at AdminAddr.
+	AdminBackups     bool           `json:"adminBackups"`     // Serve downloads and restores of the roster at AdminAddr.
+	AdminAddr        string         `json:"adminAddr"`        // Address of the admin listener, never exposed on Port. Keep it on loopback or a private network.
+	EventName        string         `json:"eventName"`        // Name of the event, heading attendance reports.
+	PhoneRegion      string         `json:"phoneRegion"`      // Default ISO 3166-1 alpha-2 region for phone numbers without a country code.
+	CustomFieldsFile string         `json:"customFieldsFile"` // Optional JSON file of models.FieldDefinitions for this deployment.
+	CSPReportOnly    bool           `json:"cspReportOnly"`    // Report Content-Security-Policy violations without enforcing the policy.
+	DevAssets        bool           `json:"devAssets"`        // Serve static files from disk for live reload, instead of the embedded copies.
+	TrustedProxies   []netip.Prefix `json:"trustedProxies"`   // Reverse proxies whose X-Forwarded-For names the client, for rate limiting.
+	DataFile         string         `json:"dataFile"`         // Optional JSON file persisting the roster. Without it contacts are seeded from ApiUrl and kept in memory.
+	SnapshotDir      string         `json:"snapshotDir"`      // Optional directory of roster snapshots, saved every SnapshotInterval and by POST /admin/backup.
+	SnapshotInterval Duration       `json:"snapshotInterval"` // How often the roster is snapshot, if changed. Zero snapshots on demand only.
+	SnapshotKeep     int            `json:"snapshotKeep"`     // Number of newest snapshots kept in SnapshotDir.
+	TLSCertFile      string         `json:"tlsCertFile"`      // PEM certificate served over HTTPS, with TLSKeyFile. Without both the server runs plain HTTP.
+	TLSKeyFile       string         `json:"tlsKeyFile"`       // PEM private key of TLSCertFile.
+	TLSSelfSigned    bool           `json:"tlsSelfSigned"`    // Generate a self-signed certificate at TLSCertFile and TLSKeyFile if missing, for LAN use.
+	RedirectPort     string         `json:"redirectPort"`     // Optional plain HTTP port redirecting to HTTPS, when TLS is on.
+
+	// Rate limits of mutations per client IP and minute, 0 for none.
+	// Clients behind one NAT share an IP and so a budget: raise the limits
+	// when several check-in desks share a network. See RateLimiter.
+	RateLimitCreate int `json:"rateLimitCreate"` // Contacts created.
+	RateLimitWrites int `json:"rateLimitWrites"` // Contacts updated or deleted and custom fields defined or removed.
+	RateLimitBulk   int `json:"rateLimitBulk"`   // Imports, merges, tag changes and backups.
+
+	// Timeouts of the server, see http.Server. WriteTimeout bounds the
+	// slowest response, e.g. an export.
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+	ReadTimeout       Duration `json:"readTimeout"`
+	WriteTimeout      Duration `json:"writeTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout"`
+	DrainDelay        Duration `json:"drainDelay"`      // How long the server reports not ready before shutting down, for load balancers to notice.
+	ShutdownTimeout   Duration `json:"shutdownTimeout"` // How long in-flight requests get to finish on shutdown.
+}
+
+// Duration is a time.Duration written as a string in config files, e.g.
+// "15s".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(time.Duration(d).String()), nil }
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ChaosEnabled reports whether the server injects faults, see Chaos.
+func (c Config) ChaosEnabled() bool { return c.Chaos || c.DebugSleep }
+
+// AdminEnabled reports whether the server runs the admin listener at
+// AdminAddr, for profiling, backups or both.
+func (c Config) AdminEnabled() bool { return c.WithProfiling || c.AdminBackups }
+
+// TLS reports whether the server runs HTTPS.
+func (c Config) TLS() bool { return c.TLSCertFile != "" && c.TLSKeyFile != "" }
+
+// DefaultConfig returns the settings used when no layer overrides them.
+func DefaultConfig() Config {
+	return Config{
+		Port:           "1234",
+		ApiUrl:         "https://jsonplaceholder.typicode.com/users",
+		Debug:          true,
+		DebugSleep:     false,
+		DebugSleepSecs: 2,
+		WithProfiling:  false,
+		PhoneRegion:    "US",
+		AdminAddr:      "localhost:6060",
+		SnapshotKeep:   24,
+
+		RateLimitCreate: 20,
+		RateLimitWrites: 60,
+		RateLimitBulk:   10,
+
+		ReadHeaderTimeout: Duration(5 * time.Second),
+		ReadTimeout:       Duration(30 * time.Second),
+		WriteTimeout:      Duration(60 * time.Second),
+		IdleTimeout:       Duration(2 * time.Minute),
+		ShutdownTimeout:   Duration(10 * time.Second),
+		SnapshotInterval:  Duration(5 * time.Minute),
+	}
+}
+
+// ConfigFlags are the command-line flags that are not settings.
+type ConfigFlags struct {
+	ConfigFile  string   // -config, also read from CONFIG_FILE.
+	Version     bool     // -v
+	PrintConfig bool     // -print-config
+	Args        []string // Non-flag arguments.
+}
+
+// LoadConfig loads the config of a command named name from its args and
+// the environment, as looked up by lookupEnv, e.g. os.LookupEnv.
+//
+// Flags are parsed twice: first to find the config file, then again over
+// the file and environment layers so that only the flags given override
+// them. Parsing -h returns flag.ErrHelp.
+//
+// register adds flags of the command besides the settings, and is called
+// for both passes.
+func LoadConfig(name string, args []string, lookupEnv func(string) (string, bool), register ...func(fs *flag.FlagSet)) (Config, ConfigFlags, error) {
+	var (
+		cfg   = DefaultConfig()
+		flags ConfigFlags
+	)
+
+	if path, ok := lookupEnv("CONFIG_FILE"); ok {
+		flags.ConfigFile = path
+	}
+
+	scratch := cfg
+	fs := newConfigFlagSet(name, &scratch, &flags, register)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return cfg, flags, err
+	}
+
+	if flags.ConfigFile != "" {
+		if err := cfg.loadFile(flags.ConfigFile); err != nil {
+			return cfg, flags, err
+		}
+	}
+	if err := cfg.loadEnv(lookupEnv); err != nil {
+		return cfg, flags, err
+	}
+
+	fs = newConfigFlagSet(name, &cfg, &flags, register)
+	if err := fs.Parse(args); err != nil {
+		return cfg, flags, err
+	}
+	flags.Args = fs.Args()
+
+	return cfg, flags, cfg.Validate()
+}
+
+func newConfigFlagSet(name string, cfg *Config, flags *ConfigFlags, register []func(fs *flag.FlagSet)) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.StringVar(&flags.ConfigFile, "config", flags.ConfigFile, "JSON config `file`")
+	fs.BoolVar(&flags.Version, "v", false, "print the build version and exit")
+	fs.BoolVar(&flags.PrintConfig, "print-config", false, "print the loaded config, secrets redacted, and exit")
+
+	fs.StringVar(&cfg.Port, "port", cfg.Port, "port to listen on")
+	fs.StringVar(&cfg.ApiUrl, "api-url", cfg.ApiUrl, "`url` of the users seeding contacts at startup")
+	fs.BoolVar(&cfg.Debug, "debug", cfg.Debug, "enable debug logging")
+	fs.BoolVar(&cfg.DebugSleep, "debug-sleep", cfg.DebugSleep, "delay every response by -debug-sleep-secs")
+	fs.IntVar(&cfg.DebugSleepSecs, "debug-sleep-secs", cfg.DebugSleepSecs, "`seconds` of -debug-sleep")
+	fs.BoolVar(&cfg.Chaos, "chaos", cfg.Chaos, "inject faults, editable at /debug/chaos, for development only")
+	fs.BoolVar(&cfg.WithProfiling, "profiling", cfg.WithProfiling, "serve pprof, expvar and /debug/routes on -admin-addr")
+	fs.BoolVar(&cfg.AdminBackups, "admin-backups", cfg.AdminBackups, "serve downloads and restores of the roster on -admin-addr")
+	fs.StringVar(&cfg.AdminAddr, "admin-addr", cfg.AdminAddr, "`address` of the admin listener")
+	fs.StringVar(&cfg.EventName, "event-name", cfg.EventName, "`name` of the event, heading attendance reports")
+	fs.StringVar(&cfg.PhoneRegion, "phone-region", cfg.PhoneRegion, "default ISO 3166-1 alpha-2 `region` of phone numbers")
+	fs.StringVar(&cfg.CustomFieldsFile, "custom-fields", cfg.CustomFieldsFile, "JSON `file` of custom field definitions")
+	fs.BoolVar(&cfg.CSPReportOnly, "csp-report-only", cfg.CSPReportOnly, "report Content-Security-Policy violations without enforcing the policy")
+	fs.BoolVar(&cfg.DevAssets, "dev", cfg.DevAssets, "serve static files from ./static for live reload")
+	fs.Func("trusted-proxies", "comma-separated `CIDRs` of reverse proxies trusted for X-Forwarded-For", func(s string) (err error) {
+		cfg.TrustedProxies, err = ParseTrustedProxies(s)
+		return err
+	})
+	fs.IntVar(&cfg.RateLimitCreate, "rate-limit-create", cfg.RateLimitCreate, "`number` of contacts each client IP may create per minute, 0 for no limit")
+	fs.IntVar(&cfg.RateLimitWrites, "rate-limit-writes", cfg.RateLimitWrites, "`number` of updates and deletes per client IP and minute, 0 for no limit")
+	fs.IntVar(&cfg.RateLimitBulk, "rate-limit-bulk", cfg.RateLimitBulk, "`number` of imports, merges, tag changes and backups per client IP and minute, 0 for no limit")
+	fs.StringVar(&cfg.DataFile, "data", cfg.DataFile, "JSON `file` persisting the roster")
+	fs.StringVar(&cfg.SnapshotDir, "snapshot-dir", cfg.SnapshotDir, "`directory` of roster snapshots")
+	fs.DurationVar((*time.Duration)(&cfg.SnapshotInterval), "snapshot-interval", time.Duration(cfg.SnapshotInterval), "`duration` between snapshots of a changed roster, 0 for on demand only")
+	fs.IntVar(&cfg.SnapshotKeep, "snapshot-keep", cfg.SnapshotKeep, "`number` of newest snapshots kept")
+	fs.StringVar(&cfg.TLSCertFile, "tls-cert", cfg.TLSCertFile, "PEM certificate `file`, enabling HTTPS with -tls-key")
+	fs.StringVar(&cfg.TLSKeyFile, "tls-key", cfg.TLSKeyFile, "PEM private key `file` of -tls-cert")
+	fs.BoolVar(&cfg.TLSSelfSigned, "tls-self-signed", cfg.TLSSelfSigned, "generate a self-signed certificate at -tls-cert and -tls-key if missing")
+	fs.StringVar(&cfg.RedirectPort, "redirect-port", cfg.RedirectPort, "plain HTTP `port` redirecting to HTTPS")
+	fs.DurationVar((*time.Duration)(&cfg.ReadHeaderTimeout), "read-header-timeout", time.Duration(cfg.ReadHeaderTimeout), "max `duration` reading request headers")
+	fs.DurationVar((*time.Duration)(&cfg.ReadTimeout), "read-timeout", time.Duration(cfg.ReadTimeout), "max `duration` reading a request, body included")
+	fs.DurationVar((*time.Duration)(&cfg.WriteTimeout), "write-timeout", time.Duration(cfg.WriteTimeout), "max `duration` writing a response")
+	fs.DurationVar((*time.Duration)(&cfg.IdleTimeout), "idle-timeout", time.Duration(cfg.IdleTimeout), "max `duration` keeping an idle connection open")
+	fs.DurationVar((*time.Duration)(&cfg.DrainDelay), "drain-delay", time.Duration(cfg.DrainDelay), "`duration` reporting not ready before shutting down")
+	fs.DurationVar((*time.Duration)(&cfg.ShutdownTimeout), "shutdown-timeout", time.Duration(cfg.ShutdownTimeout), "max `duration` for in-flight requests to finish on shutdown")
+
+	for _, fn := range register {
+		fn(fs)
+	}
+	return fs
+}
+
+// loadFile overrides c with the settings of the JSON file at path. Unknown
+// settings are rejected to catch typos.
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("error decoding config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides c with the environment variables that are set.
+func (c *Config) loadEnv(lookupEnv func(string) (string, bool)) error {
+	var errs []error
+
+	str := func(key string, dst *string) {
+		if v, ok := lookupEnv(key); ok {
+			*dst = v
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v, ok := lookupEnv(key); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = b
+		}
+	}
+	integer := func(key string, dst *int) {
+		if v, ok := lookupEnv(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = n
+		}
+	}
+
+	duration := func(key string, dst *Duration) {
+		if v, ok := lookupEnv(key); ok {
+			if err := dst.UnmarshalText([]byte(v)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+
+	str("PORT", &c.Port)
+	str("API_URL", &c.ApiUrl)
+	boolean("DEBUG", &c.Debug)
+	boolean("DEBUG_SLEEP", &c.DebugSleep)
+	integer("DEBUG_SLEEP_SECS", &c.DebugSleepSecs)
+	boolean("CHAOS", &c.Chaos)
+	boolean("WITH_PROFILING", &c.WithProfiling)
+	boolean("ADMIN_BACKUPS", &c.AdminBackups)
+	str("ADMIN_ADDR", &c.AdminAddr)
+	str("EVENT_NAME", &c.EventName)
+	str("PHONE_REGION", &c.PhoneRegion)
+	str("CUSTOM_FIELDS_FILE", &c.CustomFieldsFile)
+	boolean("CSP_REPORT_ONLY", &c.CSPReportOnly)
+	boolean("DEV_ASSETS", &c.DevAssets)
+	integer("RATE_LIMIT_CREATE", &c.RateLimitCreate)
+	integer("RATE_LIMIT_WRITES", &c.RateLimitWrites)
+	integer("RATE_LIMIT_BULK", &c.RateLimitBulk)
+	str("DATA_FILE", &c.DataFile)
+	str("SNAPSHOT_DIR", &c.SnapshotDir)
+	duration("SNAPSHOT_INTERVAL", &c.SnapshotInterval)
+	integer("SNAPSHOT_KEEP", &c.SnapshotKeep)
+	str("TLS_CERT_FILE", &c.TLSCertFile)
+	str("TLS_KEY_FILE", &c.TLSKeyFile)
+	boolean("TLS_SELF_SIGNED", &c.TLSSelfSigned)
+	str("REDIRECT_PORT", &c.RedirectPort)
+	duration("READ_HEADER_TIMEOUT", &c.ReadHeaderTimeout)
+	duration("READ_TIMEOUT", &c.ReadTimeout)
+	duration("WRITE_TIMEOUT", &c.WriteTimeout)
+	duration("IDLE_TIMEOUT", &c.IdleTimeout)
+	duration("DRAIN_DELAY", &c.DrainDelay)
+	duration("SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	if v, ok := lookupEnv("TRUSTED_PROXIES"); ok {
+		prefixes, err := ParseTrustedProxies(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+		}
+		c.TrustedProxies = prefixes
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("port: invalid port %q", c.Port))
+	}
+	if u, err := url.Parse(c.ApiUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("apiUrl: want an http or https url, got %q", redactURL(c.ApiUrl)))
+	}
+	if c.DebugSleepSecs < 0 {
+		errs = append(errs, fmt.Errorf("debugSleepSecs: must not be negative, got %d", c.DebugSleepSecs))
+	}
+	if !IsPhoneRegion(c.PhoneRegion) {
+		errs = append(errs, fmt.Errorf("phoneRegion: unsupported region %q", c.PhoneRegion))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") || (c.TLSSelfSigned && !c.TLS()) {
+		errs = append(errs, errors.New("tlsCertFile, tlsKeyFile: set both to enable TLS"))
+	}
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"readHeaderTimeout", c.ReadHeaderTimeout},
+		{"readTimeout", c.ReadTimeout},
+		{"writeTimeout", c.WriteTimeout},
+		{"idleTimeout", c.IdleTimeout},
+		{"drainDelay", c.DrainDelay},
+		{"shutdownTimeout", c.ShutdownTimeout},
+		{"snapshotInterval", c.SnapshotInterval},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", d.name, time.Duration(d.value)))
+		}
+	}
+	for _, rule := range c.ChaosRules {
+		if err := rule.Check(); err != nil {
+			errs = append(errs, fmt.Errorf("chaosRules %q: %w", rule.Pattern, err))
+		}
+	}
+	if c.AdminEnabled() {
+		if _, port, err := net.SplitHostPort(c.AdminAddr); err != nil {
+			errs = append(errs, fmt.Errorf("adminAddr: %w", err))
+		} else if port == c.Port || port == c.RedirectPort {
+			errs = append(errs, fmt.Errorf("adminAddr: port %s already serves the public site", port))
+		}
+	}
+	for _, l := range []struct {
+		name  string
+		value int
+	}{
+		{"rateLimitCreate", c.RateLimitCreate},
+		{"rateLimitWrites", c.RateLimitWrites},
+		{"rateLimitBulk", c.RateLimitBulk},
+	} {
+		if l.value < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %d", l.name, l.value))
+		}
+	}
+	if c.SnapshotKeep < 1 {
+		errs = append(errs, fmt.Errorf("snapshotKeep: keep at least 1 snapshot, got %d", c.SnapshotKeep))
+	}
+	if c.RedirectPort != "" {
+		if port, err := strconv.Atoi(c.RedirectPort); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("redirectPort: invalid port %q", c.RedirectPort))
+		} else if !c.TLS() {
+			errs = append(errs, errors.New("redirectPort: requires TLS"))
+		} else if c.RedirectPort == c.Port {
+			errs = append(errs, fmt.Errorf("redirectPort: same as port %s", c.Port))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns a copy of c safe to print, with secrets masked.
+func (c Config) Redacted() Config {
+	c.ApiUrl = redactURL(c.ApiUrl)
+	return c
+}
+
+// String returns c as indented JSON, secrets redacted.
+func (c Config) String() string {
+	b, _ := json.MarshalIndent(c.Redacted(), "", "  ")
+	return string(b)
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "[invalid url]"
+	}
+	return u.Redacted()
 }
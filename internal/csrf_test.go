@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	var gotErr error
+	protect := CSRF(func(w http.ResponseWriter, r *http.Request, err error) {
+		gotErr = err
+		w.WriteHeader(http.StatusForbidden)
+	})
+	handler := protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CSRFToken(r.Context())))
+	}))
+
+	// A safe request issues a token in a cookie and the request context.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CSRFCookieName {
+		t.Fatalf("got cookies %v, want %s", cookies, CSRFCookieName)
+	}
+	token := cookies[0].Value
+	if rec.Body.String() != token {
+		t.Fatalf("got context token %q, want cookie token %q", rec.Body.String(), token)
+	}
+
+	tests := []struct {
+		name    string
+		header  map[string]string
+		form    url.Values
+		cookie  bool
+		wantErr error
+	}{
+		{"Header token", map[string]string{CSRFHeaderName: token}, nil, true, nil},
+		{"Form token", nil, url.Values{CSRFFormField: {token}}, true, nil},
+		{"Same origin", map[string]string{CSRFHeaderName: token, "Origin": "http://example.com", "Sec-Fetch-Site": "same-origin"}, nil, true, nil},
+		{"Missing token", nil, nil, true, ErrCSRFToken},
+		{"Wrong token", map[string]string{CSRFHeaderName: token + "x"}, nil, true, ErrCSRFToken},
+		{"Missing cookie", map[string]string{CSRFHeaderName: token}, nil, false, ErrCSRFToken},
+		{"Cross site", map[string]string{CSRFHeaderName: token, "Sec-Fetch-Site": "cross-site"}, nil, true, ErrCSRFCrossOrigin},
+		{"Other origin", map[string]string{CSRFHeaderName: token, "Origin": "https://evil.example"}, nil, true, ErrCSRFCrossOrigin},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotErr = nil
+
+			req := httptest.NewRequest("POST", "http://example.com/contacts", strings.NewReader(test.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			if test.cookie {
+				req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: token})
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if !errors.Is(gotErr, test.wantErr) || (test.wantErr == nil && gotErr != nil) {
+				t.Errorf("got error %v, want %v", gotErr, test.wantErr)
+			}
+			if test.wantErr == nil && rec.Code != http.StatusOK {
+				t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package htmx reads htmx request headers and writes htmx response headers.
+//
+// See https://htmx.org/reference/#request_headers and
+// https://htmx.org/reference/#response_headers.
+package htmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Request headers sent by htmx.
+const (
+	HeaderRequest        = "HX-Request"
+	HeaderBoosted        = "HX-Boosted"
+	HeaderTarget         = "HX-Target"
+	HeaderTrigger        = "HX-Trigger" // Also a response header, see Trigger.
+	HeaderCurrentURL     = "HX-Current-URL"
+	HeaderHistoryRestore = "HX-History-Restore-Request"
+)
+
+// Response headers read by htmx.
+const (
+	HeaderRedirect           = "HX-Redirect"
+	HeaderRefresh            = "HX-Refresh"
+	HeaderReswap             = "HX-Reswap"
+	HeaderRetarget           = "HX-Retarget"
+	HeaderPushURL            = "HX-Push-Url"
+	HeaderReplaceURL         = "HX-Replace-Url"
+	HeaderTriggerAfterSwap   = "HX-Trigger-After-Swap"
+	HeaderTriggerAfterSettle = "HX-Trigger-After-Settle"
+)
+
+// IsRequest reports whether r was issued by htmx.
+func IsRequest(r *http.Request) bool { return r.Header.Get(HeaderRequest) == "true" }
+
+// IsBoosted reports whether r was issued by an hx-boost link or form, i.e. is
+// regular page navigation made by htmx.
+func IsBoosted(r *http.Request) bool { return r.Header.Get(HeaderBoosted) == "true" }
+
+// IsPartial reports whether r expects a fragment rather than a full page.
+func IsPartial(r *http.Request) bool { return IsRequest(r) && !IsBoosted(r) && !IsHistoryRestore(r) }
+
+// IsHistoryRestore reports whether r restores a page missing from the
+// client's history cache, which needs the full page.
+func IsHistoryRestore(r *http.Request) bool { return r.Header.Get(HeaderHistoryRestore) == "true" }
+
+// TriggerID returns the id of the element that triggered r, if any.
+func TriggerID(r *http.Request) string { return r.Header.Get(HeaderTrigger) }
+
+// Target returns the id of the target element of r, if any.
+func Target(r *http.Request) string { return r.Header.Get(HeaderTarget) }
+
+// CurrentURL returns the URL of the browser when r was issued, if any.
+func CurrentURL(r *http.Request) string { return r.Header.Get(HeaderCurrentURL) }
+
+// Trigger triggers the client side event name as soon as the response is
+// received. A non-nil detail is sent as JSON and becomes the event's detail.
+//
+// Triggering several events merges them into one HX-Trigger header.
+func Trigger(w http.ResponseWriter, name string, detail any) error {
+	return trigger(w, HeaderTrigger, name, detail)
+}
+
+// TriggerAfterSwap is Trigger, after the response is swapped in.
+func TriggerAfterSwap(w http.ResponseWriter, name string, detail any) error {
+	return trigger(w, HeaderTriggerAfterSwap, name, detail)
+}
+
+// TriggerAfterSettle is Trigger, after the swapped content settled.
+func TriggerAfterSettle(w http.ResponseWriter, name string, detail any) error {
+	return trigger(w, HeaderTriggerAfterSettle, name, detail)
+}
+
+// trigger adds event name to header, which holds either a comma separated
+// list of event names or a JSON object mapping names to details.
+func trigger(w http.ResponseWriter, header, name string, detail any) error {
+	events := map[string]json.RawMessage{}
+
+	if existing := strings.TrimSpace(w.Header().Get(header)); strings.HasPrefix(existing, "{") {
+		if err := json.Unmarshal([]byte(existing), &events); err != nil {
+			return err
+		}
+	} else if existing != "" {
+		for _, event := range strings.Split(existing, ",") {
+			events[strings.TrimSpace(event)] = json.RawMessage("null")
+		}
+	}
+
+	b, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	events[name] = b
+
+	value, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set(header, string(value))
+	return nil
+}
+
+// Redirect makes htmx navigate to url with a full page load.
+func Redirect(w http.ResponseWriter, url string) { w.Header().Set(HeaderRedirect, url) }
+
+// Refresh makes htmx reload the current page.
+func Refresh(w http.ResponseWriter) { w.Header().Set(HeaderRefresh, "true") }
+
+// Reswap overrides hx-swap of the request, e.g. with "outerHTML".
+func Reswap(w http.ResponseWriter, strategy string) { w.Header().Set(HeaderReswap, strategy) }
+
+// Retarget overrides hx-target of the request with a CSS selector.
+func Retarget(w http.ResponseWriter, selector string) { w.Header().Set(HeaderRetarget, selector) }
+
+// PushURL pushes url into the browser history.
+func PushURL(w http.ResponseWriter, url string) { w.Header().Set(HeaderPushURL, url) }
+
+// ReplaceURL replaces the current URL in the browser history.
+func ReplaceURL(w http.ResponseWriter, url string) { w.Header().Set(HeaderReplaceURL, url) }
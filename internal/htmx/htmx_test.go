@@ -0,0 +1,78 @@
+package htmx
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPartial(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"Plain request", nil, false},
+		{"htmx request", map[string]string{HeaderRequest: "true"}, true},
+		{"Boosted navigation", map[string]string{HeaderRequest: "true", HeaderBoosted: "true"}, false},
+		{"History restore", map[string]string{HeaderRequest: "true", HeaderHistoryRestore: "true"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range test.headers {
+				r.Header.Set(k, v)
+			}
+
+			if got := IsPartial(r); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTriggerMergesEvents(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(HeaderTrigger, "first, second")
+
+	if err := Trigger(w, "contactsChanged", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Trigger(w, "showToast", map[string]string{"message": "Saved"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(w.Header().Get(HeaderTrigger)), &got); err != nil {
+		t.Fatalf("invalid %s header %q: %v", HeaderTrigger, w.Header().Get(HeaderTrigger), err)
+	}
+
+	for _, name := range []string{"first", "second", "contactsChanged"} {
+		if v, ok := got[name]; !ok || v != nil {
+			t.Errorf("got %s = %v, %t, want null", name, v, ok)
+		}
+	}
+
+	toast, ok := got["showToast"].(map[string]any)
+	if !ok || toast["message"] != "Saved" {
+		t.Errorf("got showToast = %v, want detail with message", got["showToast"])
+	}
+}
+
+func TestTriggerHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	_ = TriggerAfterSwap(w, "swapped", nil)
+	_ = TriggerAfterSettle(w, "settled", nil)
+
+	if got, want := w.Header().Get(HeaderTriggerAfterSwap), `{"swapped":null}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get(HeaderTriggerAfterSettle), `{"settled":null}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := w.Header().Get(HeaderTrigger); got != "" {
+		t.Errorf("got %s %q, want none", HeaderTrigger, got)
+	}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Lifecycle tracks whether the server takes traffic. Once draining it
+// reports not ready, so that load balancers stop routing to it, and tells
+// long-lived handlers to wrap up.
+type Lifecycle struct {
+	ready    atomic.Bool
+	once     sync.Once
+	draining chan struct{}
+}
+
+// NewLifecycle returns a Lifecycle that is not ready yet, see SetReady.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{draining: make(chan struct{})}
+}
+
+// SetReady reports the server ready, once it listens, unless draining.
+func (l *Lifecycle) SetReady() {
+	select {
+	case <-l.draining:
+	default:
+		l.ready.Store(true)
+	}
+}
+
+// Ready reports whether the server is ready and not draining.
+func (l *Lifecycle) Ready() bool { return l.ready.Load() }
+
+// Drain reports the server not ready and closes Draining. Calling it again
+// does nothing.
+func (l *Lifecycle) Drain() {
+	l.once.Do(func() {
+		l.ready.Store(false)
+		close(l.draining)
+	})
+}
+
+// Draining is closed when the server starts shutting down.
+//
+// Streaming handlers, e.g. of server-sent events, must end their response
+// on it, after hinting clients to reconnect, e.g. with the retry field of
+// an event: http.Server.Shutdown waits for them otherwise.
+func (l *Lifecycle) Draining() <-chan struct{} { return l.draining }
+
+// ReadyHandler answers 200 while ready, else 503 with Retry-After, for
+// readiness probes.
+func (l *Lifecycle) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !l.Ready() {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"unavailable"}` + "\n"))
+			return
+		}
+		w.Write([]byte(`{"status":"ready"}` + "\n"))
+	})
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLifecycle(t *testing.T) {
+	l := NewLifecycle()
+
+	probe := func() int {
+		w := httptest.NewRecorder()
+		l.ReadyHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return w.Code
+	}
+
+	if code := probe(); code != http.StatusServiceUnavailable {
+		t.Errorf("before SetReady: got %d, want 503", code)
+	}
+
+	l.SetReady()
+	if code := probe(); code != http.StatusOK {
+		t.Errorf("ready: got %d, want 200", code)
+	}
+
+	l.Drain()
+	l.Drain() // Must not panic closing Draining twice.
+	if code := probe(); code != http.StatusServiceUnavailable {
+		t.Errorf("draining: got %d, want 503", code)
+	}
+	select {
+	case <-l.Draining():
+	default:
+		t.Error("Draining: got open, want closed")
+	}
+}
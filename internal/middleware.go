@@ -9,31 +9,115 @@
 package internal
 
 import (
+	"bufio"
+	"compress/flate"
 	"compress/gzip"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-type gzipResponseWriter struct {
-	io.Writer
-	http.ResponseWriter
-
-	// wroteHeader bool
-	// status      int // status code
-}
-
 const (
-	gzipScheme = "gzip"
+	gzipScheme    = "gzip"
+	deflateScheme = "deflate"
 
 	headerVary            = "Vary"
 	headerContentEncoding = "Content-Encoding"
 	headerAcceptEncoding  = "Accept-Encoding"
 	headerContentType     = "Content-Type"
 	headerContentLength   = "Content-Length"
+	headerETag            = "ETag"
 )
 
-// Gzip implements gzip encoding for any http.Handler via "compress/gzip".
+// DefaultCompressibleTypes are the media types compressed when
+// Compression.ContentTypes is empty. Types ending in "/" match by prefix.
+var DefaultCompressibleTypes = []string{
+	"text/",
+	"application/javascript",
+	"application/json",
+	"application/xml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// Compression configures Compress. The zero value compresses responses of
+// every size and of the DefaultCompressibleTypes at the default level.
+type Compression struct {
+	// MinSize is the smallest body in bytes worth compressing. Smaller
+	// bodies are sent as is, as compressing them costs more than it saves.
+	MinSize int
+	// ContentTypes lists the media types to compress, e.g. "text/" or
+	// "application/json". Images, archives and other compressed formats
+	// should never be listed.
+	ContentTypes []string
+	// Level is the gzip and deflate compression level, where 0 means the
+	// default level.
+	Level int
+}
+
+// Compress returns middleware compressing responses with gzip or deflate,
+// whichever the client's Accept-Encoding prefers, honoring q-values.
+//
+// Responses are sent as is if they have no body (HEAD, 204, 304), are
+// already encoded, e.g. precompressed files, answer range requests, are not
+// of an allowed content type, or are smaller than MinSize. Writers are
+// pooled, and the response writer keeps supporting http.Flusher and
+// http.Hijacker.
+//
+// Usage
+//
+//	compress := internal.Compress(internal.Compression{MinSize: 1024})
+//	http.ListenAndServe(":8080", compress(mux))
+func Compress(c Compression) func(http.Handler) http.Handler {
+	if len(c.ContentTypes) == 0 {
+		c.ContentTypes = DefaultCompressibleTypes
+	}
+	if c.Level == 0 {
+		c.Level = flate.DefaultCompression
+	}
+
+	pools := map[string]*sync.Pool{
+		gzipScheme: {New: func() any {
+			zw, err := gzip.NewWriterLevel(io.Discard, c.Level)
+			if err != nil {
+				zw = gzip.NewWriter(io.Discard)
+			}
+			return zw
+		}},
+		deflateScheme: {New: func() any {
+			zw, err := flate.NewWriter(io.Discard, c.Level)
+			if err != nil {
+				zw, _ = flate.NewWriter(io.Discard, flate.DefaultCompression)
+			}
+			return zw
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			AddVary(w.Header(), headerAcceptEncoding)
+
+			encoding := NegotiateEncoding(r.Header.Get(headerAcceptEncoding), gzipScheme, deflateScheme)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, config: &c, encoding: encoding, pool: pools[encoding]}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// Gzip compresses the responses of next with Compress and the zero
+// Compression, i.e. regardless of their size.
 //
 // Usage
 //
@@ -46,30 +130,228 @@ const (
 //		http.ListenAndServe(":8080", Gzip(handler))
 //	}
 func Gzip(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get(headerAcceptEncoding), gzipScheme) {
-			next.ServeHTTP(w, r)
-			return
+	return Compress(Compression{})(next)
+}
+
+// AddVary adds field to the Vary header of h unless listed already.
+func AddVary(h http.Header, field string) {
+	for _, value := range h.Values(headerVary) {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), field) {
+				return
+			}
 		}
+	}
+	h.Add(headerVary, field)
+}
 
-		w.Header().Set(headerContentEncoding, gzipScheme)
+// NegotiateEncoding returns the content coding of offers, in order of
+// server preference, that the Accept-Encoding header accept ranks highest,
+// or "" if the client accepts none of them.
+//
+// Codings with q=0 are refused, and "*" stands for every coding not listed.
+func NegotiateEncoding(accept string, offers ...string) string {
+	qualities := make(map[string]float64)
+	wildcard := -1.0
 
-		gw := gzip.NewWriter(w)
-		defer gw.Close()
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
 
-		grw := gzipResponseWriter{ResponseWriter: w, Writer: gw}
-		next.ServeHTTP(grw, r)
-	})
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		if coding == "*" {
+			wildcard = q
+		} else {
+			qualities[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, ok := qualities[offer]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// compressor is implemented by *gzip.Writer and *flate.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter buffers the start of the body until it can tell whether
+// the response is worth compressing, then commits the headers.
+type compressWriter struct {
+	http.ResponseWriter
+
+	config   *Compression
+	encoding string
+	pool     *sync.Pool
+
+	status    int
+	buf       []byte
+	committed bool
+	zw        compressor // Set once committed to compressing.
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status) // Informational, e.g. 103 Early Hints.
+		return
+	}
+	if w.committed || w.status != 0 {
+		return
+	}
+	w.status = status
+
+	if !bodyAllowed(status) {
+		w.commit(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.committed {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.config.MinSize {
+			return len(b), nil
+		}
+		if err := w.commitBuffered(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
 }
 
-func (w gzipResponseWriter) Write(b []byte) (int, error) {
-	return w.Writer.Write(b)
+// Flush commits the response, compressing it if eligible whatever its size
+// so far, and flushes what is written to the client.
+func (w *compressWriter) Flush() {
+	if !w.committed {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		_ = w.commitBuffered(true)
+	}
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// Close commits a response still buffered and ends the compressed stream.
+func (w *compressWriter) Close() error {
+	if !w.committed {
+		if w.status == 0 && len(w.buf) == 0 {
+			return nil // Nothing was written, e.g. the connection was hijacked.
+		}
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.commitBuffered(len(w.buf) >= w.config.MinSize && len(w.buf) > 0); err != nil {
+			return err
+		}
+	}
+
+	if w.zw == nil {
+		return nil
+	}
+	err := w.zw.Close()
+	w.zw.Reset(io.Discard)
+	w.pool.Put(w.zw)
+	w.zw = nil
+	return err
+}
+
+// commitBuffered commits the response and writes the buffered body.
+func (w *compressWriter) commitBuffered(compress bool) error {
+	if w.Header().Get(headerContentType) == "" && len(w.buf) > 0 {
+		w.Header().Set(headerContentType, http.DetectContentType(w.buf))
+	}
+	w.commit(compress)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.zw != nil {
+		_, err := w.zw.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// commit writes the headers, compressing the body if compress is set and
+// the response is eligible.
+func (w *compressWriter) commit(compress bool) {
+	w.committed = true
+	h := w.Header()
+
+	if compress && bodyAllowed(w.status) && h.Get(headerContentEncoding) == "" && w.compressible(h.Get(headerContentType)) {
+		h.Set(headerContentEncoding, w.encoding)
+		h.Del(headerContentLength)
+		// The compressed body differs byte for byte from the one the
+		// handler's ETag describes.
+		if etag := h.Get(headerETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set(headerETag, "W/"+etag)
+		}
+
+		w.zw = w.pool.Get().(compressor)
+		w.zw.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressWriter) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(w.config.ContentTypes, func(allowed string) bool {
+		if strings.HasSuffix(allowed, "/") {
+			return strings.HasPrefix(mediaType, allowed)
+		}
+		return mediaType == allowed
+	})
 }
 
-// Note: unimplemented
-func (w gzipResponseWriter) WriteHeader(status int) {
-	w.Header().Del(headerContentLength)
-	// w.wroteHeader = true
-	// w.status = status
-	w.ResponseWriter.WriteHeader(status) // Write status code
+// bodyAllowed reports whether a final response with status may have a body.
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
 }
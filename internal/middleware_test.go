@@ -2,12 +2,15 @@ package internal
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func decodeGzip(input *bytes.Buffer) (string, error) {
@@ -125,3 +128,182 @@ func TestGzipMiddlewareV2(t *testing.T) {
 		})
 	})
 }
+
+func TestGzipSkipsRangeRequests(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "hello.txt", time.Time{}, strings.NewReader("Hello, World!"))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-4")
+
+	rec := httptest.NewRecorder()
+	Gzip(handler).ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Expected no Content-Encoding for a range request, got %s", got)
+	}
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "Hello" {
+		t.Errorf("Expected 206 with %q, got %d with %q", "Hello", rec.Code, rec.Body.String())
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept, want string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"GZIP;Q=0.8, deflate;q=0.2", "gzip"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+		{"*;q=0.1, gzip;q=0", "deflate"},
+		{"br, zstd", ""},
+	}
+
+	for _, test := range tests {
+		if got := NegotiateEncoding(test.accept, gzipScheme, deflateScheme); got != test.want {
+			t.Errorf("NegotiateEncoding(%q): got %q, want %q", test.accept, got, test.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("Hello, World! ", 100)
+
+	tests := []struct {
+		name         string
+		accept       string
+		contentType  string
+		status       int
+		body         string
+		wantEncoding string
+	}{
+		{"Large text", "gzip", "text/html; charset=utf-8", 200, large, gzipScheme},
+		{"Deflate", "deflate", "text/html", 200, large, deflateScheme},
+		{"Below MinSize", "gzip", "text/html", 200, "Hello", ""},
+		{"Already compressed type", "gzip", "image/png", 200, large, ""},
+		{"Sniffed type", "gzip", "", 200, large, gzipScheme},
+		{"No content", "gzip", "text/html", http.StatusNoContent, "", ""},
+		{"Not modified", "gzip", "text/html", http.StatusNotModified, "", ""},
+		{"Error page", "gzip", "text/html", http.StatusNotFound, large, gzipScheme},
+	}
+
+	compress := Compress(Compression{MinSize: 100})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.contentType != "" {
+					w.Header().Set("Content-Type", test.contentType)
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(test.body)))
+				w.WriteHeader(test.status)
+				io.WriteString(w, test.body)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", test.accept)
+			rec := httptest.NewRecorder()
+			compress(handler).ServeHTTP(rec, req)
+
+			if rec.Code != test.status {
+				t.Errorf("status: got %d, want %d", rec.Code, test.status)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != test.wantEncoding {
+				t.Fatalf("Content-Encoding: got %q, want %q", got, test.wantEncoding)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary: got %q, want Accept-Encoding", got)
+			}
+
+			var body io.Reader = rec.Body
+			switch test.wantEncoding {
+			case gzipScheme:
+				if rec.Header().Get("Content-Length") != "" {
+					t.Error("Content-Length kept on a compressed response")
+				}
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			case deflateScheme:
+				body = flate.NewReader(rec.Body)
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.body {
+				t.Errorf("body: got %d bytes, want %d", len(got), len(test.body))
+			}
+		})
+	}
+}
+
+func TestCompressKeepsEncodedResponses(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("precompressed"))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	Gzip(handler).ServeHTTP(rec, req)
+
+	if rec.Body.String() != "precompressed" {
+		t.Errorf("Expected the body as is, got %q", rec.Body.String())
+	}
+}
+
+func TestCompressWeakensETag(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte("Hello, World!"))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	Gzip(handler).ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("ETag"); got != `W/"abc"` {
+		t.Errorf("Expected a weak ETag, got %s", got)
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: 1\n\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	Compress(Compression{MinSize: 1024})(handler).ServeHTTP(rec, req)
+
+	if !rec.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != gzipScheme {
+		t.Errorf("Expected a flushed stream to be compressed, got Content-Encoding %q", got)
+	}
+	body, err := decodeGzip(rec.Body)
+	if err != nil || body != "data: 1\n\n" {
+		t.Errorf("Expected %q, got %q (%v)", "data: 1\n\n", body, err)
+	}
+}
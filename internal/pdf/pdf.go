@@ -0,0 +1,291 @@
+// Package pdf writes minimal PDF documents of text, lines and shaded boxes,
+// without dependencies.
+//
+// Text is set in the standard Helvetica fonts, which every PDF reader has,
+// so no fonts are embedded. They cover Windows-1252 (WinAnsiEncoding):
+// other characters are written as "?".
+//
+// Coordinates are in points, 72 to the inch, from the top left corner of
+// the page, with y going down.
+//
+// Usage
+//
+//	doc := pdf.New(pdf.A4)
+//	doc.Title = "Attendance"
+//	page := doc.AddPage()
+//	page.Text(40, 60, pdf.Bold, 14, "Attendance")
+//	page.Line(40, 66, doc.Size.Width-40, 66, 0.5)
+//	err := doc.Write(w)
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Size is the size of a page in points.
+type Size struct{ Width, Height float64 }
+
+// Page sizes, portrait.
+var (
+	A4     = Size{595.28, 841.89}
+	Letter = Size{612, 792}
+)
+
+// Font is one of the standard fonts text is set in.
+type Font int
+
+const (
+	Regular Font = iota // Helvetica
+	Bold                // Helvetica-Bold
+)
+
+var fontNames = [...]string{Regular: "Helvetica", Bold: "Helvetica-Bold"}
+
+// Document is a PDF of pages, written with Write.
+type Document struct {
+	Size    Size
+	Title   string
+	Creator string    // Application that made the document, e.g. "headcount v1.2".
+	Created time.Time // Creation date, or the time of Write if zero.
+
+	pages []*Page
+}
+
+// New returns an empty document of pages of size.
+func New(size Size) *Document { return &Document{Size: size} }
+
+// AddPage appends a blank page.
+func (d *Document) AddPage() *Page {
+	p := &Page{size: d.Size}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the number of pages added.
+func (d *Document) Pages() int { return len(d.pages) }
+
+// Page returns the page at index i, from 0, e.g. to number the pages once
+// all are added.
+func (d *Document) Page(i int) *Page { return d.pages[i] }
+
+// Page is a page of a Document. Draw on it in any order, until Write.
+type Page struct {
+	size    Size
+	content bytes.Buffer
+}
+
+// Text draws s with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, num(size), num(x), num(p.size.Height-y), escape(encode(s)))
+}
+
+// TextRight draws s with its baseline ending at x, y.
+func (p *Page) TextRight(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s), y, font, size, s)
+}
+
+// Line draws a black line width points thick from x1, y1 to x2, y2.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(p.size.Height-y1), num(x2), num(p.size.Height-y2))
+}
+
+// Box fills a rectangle with its top left corner at x, y in gray, from 0
+// for black to 1 for white.
+func (p *Page) Box(x, y, width, height, gray float64) {
+	fmt.Fprintf(&p.content, "q %s g %s %s %s %s re f Q\n",
+		num(gray), num(x), num(p.size.Height-y-height), num(width), num(height))
+}
+
+// TextWidth returns the width of s set in font at size.
+func TextWidth(font Font, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == Bold {
+		widths = &helveticaBoldWidths
+	}
+
+	var units int
+	for _, b := range []byte(encode(s)) {
+		if b >= 32 && b < 127 {
+			units += int(widths[b-32])
+		} else {
+			units += defaultWidth
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Truncate shortens s with "..." to fit width, when set in font at size.
+func Truncate(font Font, size float64, s string, width float64) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	for s != "" {
+		_, n := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-n]
+		if TextWidth(font, size, s+"...") <= width {
+			return s + "..."
+		}
+	}
+	return ""
+}
+
+// Write writes d as a PDF file.
+func (d *Document) Write(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	created := d.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3 info, 4-5 fonts, then a page and
+	// its content stream for each page.
+	const firstPage = 6
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), num(d.Size.Width), num(d.Size.Height)))
+
+	object(fmt.Sprintf("<< /Title (%s) /Creator (%s) /CreationDate (%s) >>",
+		escape(encode(d.Title)), escape(encode(d.Creator)), pdfDate(created)))
+
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+
+	for i, p := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(p.content.Bytes())
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// num formats n with at most two decimals, as PDF has no exponents.
+func num(n float64) string {
+	s := strconv.FormatFloat(n, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfDate formats t as a PDF date, e.g. "D:20240101180000+05'30'".
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("D:%s%c%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// encode converts s from UTF-8 to Windows-1252, replacing characters it
+// lacks with "?".
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// escape escapes the delimiters of a PDF string and replaces control
+// characters with spaces.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c == 127:
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// winAnsi maps the characters of Windows-1252 from 0x80 to 0x9F.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// defaultWidth approximates the width, in thousandths of the font size, of
+// characters outside ASCII.
+const defaultWidth = 556
+
+// Widths of the ASCII characters from space to tilde, in thousandths of the
+// font size, from the Adobe font metrics of the standard fonts.
+var (
+	helveticaWidths = [95]uint16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]uint16{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
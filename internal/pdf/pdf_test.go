@@ -0,0 +1,107 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		font     Font
+		size     float64
+		s        string
+		expected float64
+	}{
+		{Regular, 10, "", 0},
+		{Regular, 10, "Hi", 10 * (722 + 222) / 1000.0},
+		{Bold, 10, "Hi", 10 * (722 + 278) / 1000.0},
+		{Regular, 1000, "é", defaultWidth},
+	}
+
+	for _, test := range tests {
+		if got := TextWidth(test.font, test.size, test.s); got != test.expected {
+			t.Errorf("TextWidth(%d, %v, %q) = %v, expected %v", test.font, test.size, test.s, got, test.expected)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate(Regular, 10, "short", 100); got != "short" {
+		t.Errorf("got %q, expected the text unchanged", got)
+	}
+
+	got := Truncate(Regular, 10, "A rather long name that overflows its column", 60)
+	if !strings.HasSuffix(got, "...") || TextWidth(Regular, 10, got) > 60 {
+		t.Errorf("got %q, %v wide, expected it cut to 60 with ...", got, TextWidth(Regular, 10, got))
+	}
+}
+
+func TestEncode(t *testing.T) {
+	if got, expected := escape(encode("Zoë (“café”) \\ 日本\n")), "Zo\xeb \\(\x93caf\xe9\x94\\) \\\\ ?? "; got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	doc := New(A4)
+	doc.Title = "Report (draft)"
+	doc.Created = time.Date(2024, time.January, 1, 18, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	for i := 0; i < 3; i++ {
+		page := doc.AddPage()
+		page.Box(40, 40, 100, 20, 0.9)
+		page.Text(40, 60, Bold, 14, "Page "+strconv.Itoa(i+1))
+		page.Line(40, 66, 555.28, 66, 0.5)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	if !bytes.HasPrefix(b, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(b, []byte("%%EOF\n")) {
+		t.Error("missing PDF header or trailer")
+	}
+	for _, want := range []string{"/Count 3", "/Title (Report \\(draft\\))", "/CreationDate (D:20240101180000+05'30')", "/BaseFont /Helvetica-Bold"} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("missing %s", want)
+		}
+	}
+
+	// Every xref entry points at its object.
+	start, err := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(b)[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(b[start:], -1)
+	if len(entries) != 5+2*3 {
+		t.Fatalf("got %d xref entries, expected %d", len(entries), 5+2*3)
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(b[offset:], []byte(want)) {
+			t.Errorf("xref entry %d: got %q, expected %q", i+1, b[offset:offset+len(want)], want)
+		}
+	}
+
+	// Content streams are compressed, with y measured from the top.
+	stream := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindSubmatchIndex(b)
+	length, _ := strconv.Atoi(string(b[stream[2]:stream[3]]))
+	zr, err := zlib.NewReader(bytes.NewReader(b[stream[1] : stream[1]+length]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "BT /F2 14 Tf 40 781.89 Td (Page 1) Tj ET"; !strings.Contains(string(content), want) {
+		t.Errorf("got content %q, expected it to contain %q", content, want)
+	}
+}
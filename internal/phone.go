@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Phone is a parsed telephone number.
+type Phone struct {
+	CountryCode string // ITU-T E.164 country calling code, e.g. "1" or "44".
+	National    string // National significant number without trunk prefix.
+	Extension   string // Optional extension digits.
+}
+
+// E164 formats p as an E.164 number, e.g. "+17707368031".
+func (p Phone) E164() string {
+	return "+" + p.CountryCode + p.National
+}
+
+// String formats p as E.164 followed by an RFC 3966 style extension
+// parameter when present, e.g. "+17707368031;ext=56442".
+//
+// The output is accepted by ParsePhone, so normalizing twice is a no-op.
+func (p Phone) String() string {
+	if p.Extension == "" {
+		return p.E164()
+	}
+	return p.E164() + ";ext=" + p.Extension
+}
+
+// phoneRegion describes the dialing plan of a region closely enough to turn
+// national numbers into E.164. It does not know about area code allocation,
+// so a well-formed but unassigned number is still accepted.
+type phoneRegion struct {
+	countryCode string
+	trunkPrefix string // Dialed before national numbers, stripped when normalizing.
+	intlPrefix  string // Dialed before international numbers, besides "+" and "00".
+	minLen      int    // Minimum national significant number length.
+	maxLen      int    // Maximum national significant number length.
+}
+
+// phoneRegions maps ISO 3166-1 alpha-2 codes to dialing plans.
+var phoneRegions = map[string]phoneRegion{
+	"AE": {countryCode: "971", trunkPrefix: "0", minLen: 8, maxLen: 9},
+	"AU": {countryCode: "61", trunkPrefix: "0", intlPrefix: "0011", minLen: 9, maxLen: 9},
+	"BR": {countryCode: "55", trunkPrefix: "0", minLen: 10, maxLen: 11},
+	"CA": {countryCode: "1", trunkPrefix: "1", intlPrefix: "011", minLen: 10, maxLen: 10},
+	"DE": {countryCode: "49", trunkPrefix: "0", minLen: 6, maxLen: 13},
+	"ES": {countryCode: "34", minLen: 9, maxLen: 9},
+	"FR": {countryCode: "33", trunkPrefix: "0", minLen: 9, maxLen: 9},
+	"GB": {countryCode: "44", trunkPrefix: "0", minLen: 9, maxLen: 10},
+	"IE": {countryCode: "353", trunkPrefix: "0", minLen: 7, maxLen: 9},
+	"IN": {countryCode: "91", trunkPrefix: "0", minLen: 10, maxLen: 10},
+	"IT": {countryCode: "39", minLen: 6, maxLen: 11},
+	"JP": {countryCode: "81", trunkPrefix: "0", intlPrefix: "010", minLen: 9, maxLen: 10},
+	"MX": {countryCode: "52", minLen: 10, maxLen: 10},
+	"NL": {countryCode: "31", trunkPrefix: "0", minLen: 9, maxLen: 9},
+	"NZ": {countryCode: "64", trunkPrefix: "0", minLen: 8, maxLen: 10},
+	"SG": {countryCode: "65", intlPrefix: "000", minLen: 8, maxLen: 8},
+	"US": {countryCode: "1", trunkPrefix: "1", intlPrefix: "011", minLen: 10, maxLen: 10},
+	"ZA": {countryCode: "27", trunkPrefix: "0", minLen: 9, maxLen: 9},
+}
+
+const (
+	// e164MaxDigits is the maximum number of digits in an E.164 number,
+	// country code included.
+	e164MaxDigits = 15
+	// e164MinDigits is a practical lower bound for international numbers.
+	e164MinDigits = 8
+)
+
+var (
+	ErrPhoneEmpty         error = errors.New("phone number is empty")
+	ErrPhoneInvalid       error = errors.New("phone number contains invalid characters")
+	ErrPhoneTooShort      error = errors.New("phone number is too short")
+	ErrPhoneTooLong       error = errors.New("phone number is too long")
+	ErrPhoneUnknownRegion error = errors.New("unknown phone region")
+)
+
+var (
+	// Matches a trailing extension such as " x56442", " ext. 12", "#12" or ";ext=12".
+	phoneExtRegexp = regexp.MustCompile(`(?i)\s*(?:;\s*ext=|ext\.?|extension|x|#)\s*(\d{1,7})\s*$`)
+	// Separators allowed between digits of the number itself.
+	phoneCharsRegexp = regexp.MustCompile(`^\+?[0-9\s\-./()]+$`)
+)
+
+// IsPhoneRegion reports whether region is a supported default region.
+func IsPhoneRegion(region string) bool {
+	_, ok := phoneRegions[strings.ToUpper(region)]
+	return ok
+}
+
+// ParsePhone parses a raw phone number such as "1-770-736-8031 x56442",
+// "(254)954-1289" or "+44 20 7946 0958".
+//
+// Numbers written with a leading "+", "00", or the region's international
+// prefix are read as international. Anything else is read as a national
+// number of defaultRegion, an ISO 3166-1 alpha-2 code like "US".
+func ParsePhone(raw, defaultRegion string) (Phone, error) {
+	region, ok := phoneRegions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return Phone{}, fmt.Errorf("%w: %q", ErrPhoneUnknownRegion, defaultRegion)
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Phone{}, ErrPhoneEmpty
+	}
+
+	var phone Phone
+
+	if m := phoneExtRegexp.FindStringSubmatchIndex(raw); m != nil {
+		phone.Extension = raw[m[2]:m[3]]
+		raw = strings.TrimSpace(raw[:m[0]])
+	}
+
+	if !phoneCharsRegexp.MatchString(raw) {
+		return Phone{}, ErrPhoneInvalid
+	}
+
+	digits := NormalizeDigits(raw)
+
+	international := strings.HasPrefix(raw, "+")
+	for _, prefix := range []string{"00", region.intlPrefix} {
+		if !international && prefix != "" && strings.HasPrefix(digits, prefix) {
+			digits = digits[len(prefix):]
+			international = true
+		}
+	}
+
+	if international {
+		if len(digits) < e164MinDigits {
+			return Phone{}, ErrPhoneTooShort
+		}
+		if len(digits) > e164MaxDigits {
+			return Phone{}, ErrPhoneTooLong
+		}
+
+		phone.CountryCode, phone.National = splitCountryCode(digits)
+		return phone, nil
+	}
+
+	if region.trunkPrefix != "" && len(digits) > region.minLen && strings.HasPrefix(digits, region.trunkPrefix) {
+		digits = digits[len(region.trunkPrefix):]
+	}
+
+	switch {
+	case len(digits) < region.minLen:
+		return Phone{}, ErrPhoneTooShort
+	case len(digits) > region.maxLen, len(region.countryCode)+len(digits) > e164MaxDigits:
+		return Phone{}, ErrPhoneTooLong
+	}
+
+	phone.CountryCode, phone.National = region.countryCode, digits
+	return phone, nil
+}
+
+// NormalizePhone parses raw with ParsePhone and returns its canonical
+// Phone.String form.
+func NormalizePhone(raw, defaultRegion string) (string, error) {
+	phone, err := ParsePhone(raw, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return phone.String(), nil
+}
+
+// splitCountryCode splits E.164 digits into the longest known country calling
+// code and the rest. Unknown codes fall back to the first digit, which is
+// enough to keep the number round-trippable.
+func splitCountryCode(digits string) (countryCode, national string) {
+	for n := 3; n >= 1; n-- {
+		for _, region := range phoneRegions {
+			if region.countryCode == digits[:n] {
+				return digits[:n], digits[n:]
+			}
+		}
+	}
+	return digits[:1], digits[1:]
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		{"1-770-736-8031 x56442", "US", "+17707368031;ext=56442"},
+		{"010-692-6593 x09125", "US", "+10106926593;ext=09125"},
+		{"(254)954-1289", "US", "+12549541289"},
+		{"586.493.6943 x140", "US", "+15864936943;ext=140"},
+		{"1029384756", "US", "+11029384756"},
+		{"+44 20 7946 0958", "US", "+442079460958"},
+		{"011 44 20 7946 0958", "US", "+442079460958"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"098765 43210", "IN", "+919876543210"},
+		{"+91 98765 43210 ext. 12", "gb", "+919876543210;ext=12"},
+		{"+17707368031;ext=56442", "US", "+17707368031;ext=56442"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := NormalizePhone(tt.raw, tt.region)
+			if err != nil {
+				t.Fatalf("NormalizePhone(%q, %q) error: %v", tt.raw, tt.region, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhone(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePhoneErrors(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   error
+	}{
+		{"", "US", ErrPhoneEmpty},
+		{"call me maybe", "US", ErrPhoneInvalid},
+		{"12345", "US", ErrPhoneTooShort},
+		{"123456789012", "US", ErrPhoneTooLong},
+		{"+1234", "US", ErrPhoneTooShort},
+		{"+1234567890123456", "US", ErrPhoneTooLong},
+		{"7707368031", "XX", ErrPhoneUnknownRegion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if _, err := NormalizePhone(tt.raw, tt.region); !errors.Is(err, tt.want) {
+				t.Errorf("NormalizePhone(%q, %q) error = %v, want %v", tt.raw, tt.region, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhoneE164(t *testing.T) {
+	phone, err := ParsePhone("1-770-736-8031 x56442", "US")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := phone.E164(), "+17707368031"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := phone.Extension, "56442"; got != want {
+		t.Errorf("got extension %q, want %q", got, want)
+	}
+}
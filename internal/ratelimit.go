@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited error = errors.New("too many requests")
+
+// Limit allows Requests per period Per, in bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l Limit) rate() float64 { return float64(l.Requests) / l.Per.Seconds() }
+
+// RateLimiterOptions configures NewRateLimiter.
+type RateLimiterOptions struct {
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// names the client IP. See ClientIP.
+	TrustedProxies []netip.Prefix
+	// Capacity bounds the buckets kept in memory, evicting the least
+	// recently used. Defaults to 10000.
+	Capacity int
+	// OnLimit writes the response to limited requests, with ErrRateLimited.
+	// Defaults to a plain 429.
+	OnLimit func(w http.ResponseWriter, r *http.Request, err error)
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// RateLimiter limits requests per client IP with token buckets, one per
+// client and route, see Limit.
+//
+// Clients behind one NAT, e.g. the check-in desks of a venue network, share
+// an IP and thus a budget, so limits must allow for all of them at once.
+//
+// Clients are not keyed by session cookie, as clients choose their cookies:
+// one sending a fresh cookie per request would get a fresh budget each time
+// and churn the buckets of everyone else out of memory.
+type RateLimiter struct {
+	opts RateLimiterOptions
+
+	mu      sync.Mutex
+	buckets *lruBuckets
+}
+
+const defaultRateLimiterCapacity = 10000
+
+// NewRateLimiter returns a RateLimiter keeping its buckets in memory.
+//
+// Usage
+//
+//	limiter := internal.NewRateLimiter(internal.RateLimiterOptions{TrustedProxies: cfg.TrustedProxies})
+//	mux.Handle("POST /contacts", limiter.Limit("contacts", internal.Limit{Requests: 30, Per: time.Minute})(handler))
+func NewRateLimiter(opts RateLimiterOptions) *RateLimiter {
+	if opts.Capacity <= 0 {
+		opts.Capacity = defaultRateLimiterCapacity
+	}
+	if opts.OnLimit == nil {
+		opts.OnLimit = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &RateLimiter{opts: opts, buckets: newLRUBuckets(opts.Capacity)}
+}
+
+// Limit returns middleware allowing each client limit on the route name.
+// Routes sharing a name share their budget.
+//
+// Every response carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers. Limited requests get Retry-After and are passed
+// to OnLimit.
+func (l *RateLimiter) Limit(name string, limit Limit) func(http.Handler) http.Handler {
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Per.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, remaining, reset, retryAfter := l.take(name+"|"+l.clientKey(r), limit)
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", policy)
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+			if !ok {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				l.opts.OnLimit(w, r, ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// take spends a token of the bucket at key, reporting whether one was left,
+// the tokens remaining, the time until the bucket is full again, and, when
+// limited, the time until the next token.
+func (l *RateLimiter) take(key string, limit Limit) (ok bool, remaining int, reset, retryAfter time.Duration) {
+	now := l.opts.Now()
+	rate, burst := limit.rate(), float64(limit.Requests)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.buckets.get(key, func() *tokenBucket { return &tokenBucket{tokens: burst, last: now} })
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		ok = true
+	} else {
+		retryAfter = seconds((1 - b.tokens) / rate)
+	}
+	return ok, int(b.tokens), seconds((burst - b.tokens) / rate), retryAfter
+}
+
+// clientKey identifies the client of r by its IP.
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	return "ip:" + ClientIP(r, l.opts.TrustedProxies)
+}
+
+// ClientIP returns the IP of the client of r. Requests from trustedProxies
+// are attributed to the rightmost address of X-Forwarded-For that is not a
+// trusted proxy itself, as proxies append the address they received the
+// request from.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(addr, trustedProxies) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !isTrusted(addr, trustedProxies) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses a comma-separated list of IPs and CIDR
+// prefixes, e.g. "10.0.0.0/8, 127.0.0.1".
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var (
+		prefixes []netip.Prefix
+		errs     []error
+	)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid trusted proxy %q: %w", entry, err))
+				continue
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid trusted proxy %q: %w", entry, err))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, errors.Join(errs...)
+}
+
+func seconds(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+
+func ceilSeconds(d time.Duration) int { return int(math.Ceil(d.Seconds())) }
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// lruBuckets holds up to capacity buckets, evicting the least recently
+// used. An evicted client starts over with a full bucket.
+type lruBuckets struct {
+	capacity int
+	order    *list.List // Of *lruEntry, most recently used first.
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	bucket *tokenBucket
+}
+
+func newLRUBuckets(capacity int) *lruBuckets {
+	return &lruBuckets{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the bucket at key, adding the one made by newBucket if
+// missing.
+func (c *lruBuckets) get(key string, newBucket func() *tokenBucket) *tokenBucket {
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*lruEntry).bucket
+	}
+
+	b := newBucket()
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, bucket: b})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return b
+}
+
+func (c *lruBuckets) len() int { return c.order.Len() }
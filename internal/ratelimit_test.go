@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 2, 7, 0, 0, 0, 0, time.UTC)
+	var limitedErr error
+
+	limiter := NewRateLimiter(RateLimiterOptions{
+		Now: func() time.Time { return now },
+		OnLimit: func(w http.ResponseWriter, r *http.Request, err error) {
+			limitedErr = err
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+	})
+	handler := limiter.Limit("create", Limit{Requests: 2, Per: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	post := func(ip, session string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/contacts", nil)
+		r.RemoteAddr = ip + ":1234"
+		if session != "" {
+			r.AddCookie(&http.Cookie{Name: "sessionID", Value: session})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post("192.0.2.1", "a"); w.Code != 200 || w.Header().Get("RateLimit-Remaining") != "1" || w.Header().Get("RateLimit-Limit") != "2" {
+		t.Fatalf("first request: got %d with remaining %q", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+	post("192.0.2.1", "a")
+
+	w := post("192.0.2.1", "a")
+	if w.Code != http.StatusTooManyRequests || !errors.Is(limitedErr, ErrRateLimited) {
+		t.Fatalf("third request: got %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After: got %q, want 30", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "60" {
+		t.Errorf("RateLimit-Reset: got %q, want 60", got)
+	}
+
+	if w := post("192.0.2.1", "forged"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("other session from the same IP: got %d, want 429", w.Code)
+	}
+	if w := post("192.0.2.2", ""); w.Code != 200 {
+		t.Errorf("other IP: got %d, want 200", w.Code)
+	}
+
+	now = now.Add(30 * time.Second)
+	if w := post("192.0.2.1", "a"); w.Code != 200 {
+		t.Errorf("after refill: got %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	buckets := newLRUBuckets(2)
+	newBucket := func() *tokenBucket { return &tokenBucket{} }
+
+	a := buckets.get("a", newBucket)
+	buckets.get("b", newBucket)
+	buckets.get("a", newBucket) // a is now the most recently used.
+	buckets.get("c", newBucket)
+
+	if got := buckets.len(); got != 2 {
+		t.Fatalf("got %d buckets, want 2", got)
+	}
+	if buckets.get("a", newBucket) != a {
+		t.Error("a was evicted, want b evicted")
+	}
+	if _, ok := buckets.entries["b"]; ok {
+		t.Error("b was kept")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1, not-an-ip")
+	if err == nil || len(trusted) != 2 {
+		t.Fatalf("got %d trusted proxies and error %v, want 2 and an error", len(trusted), err)
+	}
+
+	tests := []struct {
+		name, remoteAddr, forwardedFor, want string
+	}{
+		{"Direct client", "203.0.113.7:1234", "", "203.0.113.7"},
+		{"Untrusted proxy is ignored", "203.0.113.7:1234", "198.51.100.1", "203.0.113.7"},
+		{"Trusted proxy", "10.0.0.2:80", "198.51.100.1", "198.51.100.1"},
+		{"Spoofed leftmost entry", "10.0.0.2:80", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"Chain of trusted proxies", "10.0.0.2:80", "198.51.100.1, 192.168.1.1, 10.0.0.3", "198.51.100.1"},
+		{"Malformed header", "10.0.0.2:80", "garbage", "10.0.0.2"},
+		{"IPv6 client", "[2001:db8::1]:443", "", "2001:db8::1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remoteAddr
+			if test.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", test.forwardedFor)
+			}
+
+			if got := ClientIP(r, trusted); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	if !isTrusted(netip.MustParseAddr("::ffff:10.1.2.3"), trusted) {
+		t.Error("IPv4-mapped address of a trusted proxy is not trusted")
+	}
+}
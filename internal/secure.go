@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CSP describes the Content-Security-Policy sent by SecureHeaders.
+type CSP struct {
+	// ReportOnly sends the policy as Content-Security-Policy-Report-Only,
+	// reporting violations without blocking anything.
+	ReportOnly bool
+	// ReportURI receives violation reports, e.g. "/csp-report".
+	ReportURI string
+}
+
+type cspNonceKeyKind string
+
+const cspNonceKey cspNonceKeyKind = "cspNonce"
+
+const cspNonceLength = 18
+
+// CSPNonce returns the nonce of the request of ctx issued by SecureHeaders.
+// Every script and style element rendered in the response must carry it.
+func CSPNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceKey).(string)
+	return nonce
+}
+
+// Header returns the policy for a response with nonce.
+//
+// Inline style attributes stay allowed, as they are used throughout the
+// templates. Scripts may eval as Alpine's standard build and hx-on need it.
+func (c CSP) Header(nonce string) string {
+	directives := []string{
+		"default-src 'self'",
+		"script-src 'self' 'nonce-" + nonce + "' 'unsafe-eval'",
+		"style-src 'self' 'nonce-" + nonce + "'",
+		"style-src-attr 'unsafe-inline'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors 'none'",
+	}
+	if c.ReportURI != "" {
+		directives = append(directives, "report-uri "+c.ReportURI)
+	}
+	return strings.Join(directives, "; ")
+}
+
+// SecureHeaders sets security headers on every response, including a
+// Content-Security-Policy with a fresh nonce per request. See CSPNonce.
+//
+// Strict-Transport-Security is only sent over TLS, as browsers ignore it
+// otherwise.
+//
+// Usage
+//
+//	secure := internal.SecureHeaders(internal.CSP{ReportURI: "/csp-report"})
+//	http.ListenAndServe(":8080", secure(mux))
+func SecureHeaders(csp CSP) func(http.Handler) http.Handler {
+	cspHeader := "Content-Security-Policy"
+	if csp.ReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce, err := GenRandStr(cspNonceLength)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("error generating nonce: %v", err), http.StatusInternalServerError)
+				return
+			}
+
+			h := w.Header()
+			h.Set(cspHeader, csp.Header(nonce))
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			h.Set("Cross-Origin-Opener-Policy", "same-origin")
+			if r.TLS != nil {
+				h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), cspNonceKey, nonce)))
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"net/mail"
 	"regexp"
+	"sort"
+	"strings"
+	"unicode"
 )
 
 func Contains(slice []string, str string) bool {
@@ -66,3 +69,122 @@ func ValidateEmail(email string) error {
 
 	return nil // Email is valid
 }
+
+// NormalizeEmail lowercases and trims an email address so that trivially
+// different spellings of the same mailbox compare equal.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeDigits strips everything but ASCII digits from s, e.g. a raw phone
+// number "1-770-736-8031" becomes "17707368031".
+func NormalizeDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// NormalizeName lowercases a name, drops punctuation and honorifics, and sorts
+// its tokens so that "Doe, John" and "john doe" normalize to "doe john".
+func NormalizeName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !Contains(nameHonorifics, f) {
+			tokens = append(tokens, f)
+		}
+	}
+	sort.Strings(tokens)
+
+	return strings.Join(tokens, " ")
+}
+
+// Slug lowercases s and joins its runs of ASCII letters and digits with
+// hyphens, e.g. for file names: "Ada Lovelace, Jr." becomes
+// "ada-lovelace-jr".
+func Slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(words, "-")
+}
+
+var nameHonorifics = []string{"mr", "mrs", "ms", "miss", "dr", "prof", "jr", "sr", "ii", "iii", "iv", "v", "md", "phd", "dds", "dvm"}
+
+// NameSimilarity reports how alike two names are in the range [0, 1] using the
+// Jaro-Winkler similarity of their normalized forms. Names that normalize to
+// nothing, e.g. "Dr." or "", are alike to no name, not even each other.
+func NameSimilarity(a, b string) float64 {
+	na, nb := NormalizeName(a), NormalizeName(b)
+	if na == "" || nb == "" {
+		return 0
+	}
+	return jaroWinkler(na, nb)
+}
+
+// jaroWinkler implements the Jaro-Winkler string similarity.
+//
+// See https://en.wikipedia.org/wiki/Jaro%E2%80%93Winkler_distance
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	window = max(window, 0)
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+
+	matches := 0
+	for i := range s1 {
+		lo, hi := max(0, i-window), min(len(s2), i+window+1)
+		for j := lo; j < hi; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, k := 0, 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < min(4, len(s1), len(s2)) && s1[i] == s2[i]; i++ {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -102,3 +102,84 @@ func TestValidateEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeEmail(t *testing.T) {
+	if got, want := NormalizeEmail("  John.Doe@Example.COM "), "john.doe@example.com"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeDigits(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1-770-736-8031 x56442", "1770736803156442"},
+		{"(254)954-1289", "2549541289"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeDigits(tt.in); got != tt.want {
+			t.Errorf("NormalizeDigits(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"John Doe", "doe john"},
+		{"Doe, John", "doe john"},
+		{"Mr. John  Doe Jr.", "doe john"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.in); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Ada Lovelace, Jr.":    "ada-lovelace-jr",
+		"  multiple   spaces ": "multiple-spaces",
+		"Zoë O'Neil":           "zo-o-neil",
+		"日本":                   "",
+	}
+
+	for input, expected := range tests {
+		if result := Slug(input); result != expected {
+			t.Errorf("Slug(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{"John Doe", "Doe, John", 1, 1.01},
+		{"Jon Doe", "John Doe", 0.9, 1},
+		{"Leanne Graham", "Leane Graham", 0.9, 1},
+		{"Leanne Graham", "Ervin Howell", 0, 0.6},
+		{"Dr.", "Mr", 0, 0.01},
+		{"", "", 0, 0.01},
+		{"Prof.", "John Doe", 0, 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			got := NameSimilarity(tt.a, tt.b)
+			if got < tt.atLeast || got >= tt.below {
+				t.Errorf("NameSimilarity(%q, %q) = %.3f, want in [%.2f, %.2f)", tt.a, tt.b, got, tt.atLeast, tt.below)
+			}
+		})
+	}
+}
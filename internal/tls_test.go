@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCreateCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls", "cert.pem"), filepath.Join(dir, "tls", "key.pem")
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	cert, err := LoadOrCreateCertificate(certFile, keyFile, []string{"localhost", "192.168.1.20"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := leaf.VerifyHostname("192.168.1.20"); err != nil {
+		t.Errorf("LAN IP: %v", err)
+	}
+	if err := leaf.VerifyHostname("localhost"); err != nil {
+		t.Errorf("localhost: %v", err)
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file: got %v, %v, want mode 0600", info, err)
+	}
+
+	// Restarts keep the saved certificate.
+	again, err := LoadOrCreateCertificate(certFile, keyFile, nil, now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Certificate[0], cert.Certificate[0]) {
+		t.Error("got a new certificate, want the saved one")
+	}
+
+	// Expired certificates are replaced.
+	renewed, err := LoadOrCreateCertificate(certFile, keyFile, nil, now.Add(2*selfSignedValidity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(renewed.Certificate[0], cert.Certificate[0]) {
+		t.Error("got the expired certificate, want a new one")
+	}
+
+	// Half a pair is never overwritten.
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateCertificate(certFile, keyFile, nil, now); err == nil {
+		t.Error("got no error with a missing key, want one")
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		port, host, target, want string
+	}{
+		{"8443", "example.com:8080", "/contacts?q=ada", "https://example.com:8443/contacts?q=ada"},
+		{"443", "example.com", "/", "https://example.com/"},
+		{"443", "[::1]:80", "/about", "https://[::1]/about"},
+		{"8443", "[::1]", "/", "https://[::1]:8443/"},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, test.target, nil)
+		r.Host = test.host
+		w := httptest.NewRecorder()
+		RedirectHTTPS(test.port).ServeHTTP(w, r)
+
+		if w.Code != http.StatusTemporaryRedirect || w.Header().Get("Location") != test.want {
+			t.Errorf("%s%s: got %d to %q, want %d to %q", test.host, test.target, w.Code, w.Header().Get("Location"), http.StatusTemporaryRedirect, test.want)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package xlsx writes minimal Office Open XML spreadsheets, readable by
+// Excel, LibreOffice and Google Sheets, without dependencies.
+//
+// Cells hold text, numbers, booleans or times. Text is always written as
+// text, never as a formula, so values starting with "=" are safe.
+//
+// Usage
+//
+//	wb := xlsx.New()
+//	sheet := wb.AddSheet("Contacts")
+//	sheet.AddHeader("Name", "Checked in")
+//	sheet.AddRow("Ada Lovelace", time.Now())
+//	err := wb.Write(w)
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of a workbook.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// maxCellText is the number of characters a cell holds at most.
+const maxCellText = 32767
+
+// Cell styles, indexes into cellXfs of stylesXML.
+const (
+	styleDefault = iota
+	styleHeader
+	styleDateTime
+	stylePercent
+)
+
+// Workbook is a spreadsheet of sheets, written with Write.
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// New returns an empty workbook.
+func New() *Workbook { return &Workbook{} }
+
+// Sheet is a worksheet of rows.
+type Sheet struct {
+	name   string
+	rows   [][]cell
+	widths map[int]float64
+	frozen bool
+}
+
+type cell struct {
+	value any
+	style int
+}
+
+// Percent is a share, from 0 to 1, shown as a percentage.
+type Percent float64
+
+// AddSheet appends a sheet named name. Characters not allowed in sheet
+// names are replaced, and names are cut to 31 characters.
+func (wb *Workbook) AddSheet(name string) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(wb.sheets)+1)
+	}
+
+	s := &Sheet{name: name, widths: map[int]float64{}}
+	wb.sheets = append(wb.sheets, s)
+	return s
+}
+
+// AddHeader appends a bold row that stays visible while scrolling. Call it
+// before AddRow.
+func (s *Sheet) AddHeader(titles ...string) {
+	row := make([]cell, len(titles))
+	for i, title := range titles {
+		row[i] = cell{value: title, style: styleHeader}
+	}
+	s.rows = append(s.rows, row)
+	s.frozen = len(s.rows) == 1
+}
+
+// AddRow appends a row of values: strings, integers, floats, booleans,
+// Percent, time.Time, or nil for an empty cell. Other values are written as
+// text with fmt.Sprint. Zero times are left empty.
+func (s *Sheet) AddRow(values ...any) {
+	row := make([]cell, len(values))
+	for i, v := range values {
+		row[i] = cell{value: v}
+		switch v := v.(type) {
+		case time.Time:
+			row[i].style = styleDateTime
+			if v.IsZero() {
+				row[i].value = nil
+			}
+		case Percent:
+			row[i].style = stylePercent
+		}
+	}
+	s.rows = append(s.rows, row)
+}
+
+// SetColumnWidth sets the width of the column at index col, from 0, in
+// characters.
+func (s *Sheet) SetColumnWidth(col int, width float64) { s.widths[col] = width }
+
+// Write writes wb as an .xlsx file.
+func (wb *Workbook) Write(w io.Writer) error {
+	if len(wb.sheets) == 0 {
+		wb.AddSheet("")
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name  string
+		write func(w *bufio.Writer) error
+	}{
+		{"[Content_Types].xml", wb.writeContentTypes},
+		{"_rels/.rels", writeString(rootRelsXML)},
+		{"xl/workbook.xml", wb.writeWorkbook},
+		{"xl/_rels/workbook.xml.rels", wb.writeWorkbookRels},
+		{"xl/styles.xml", writeString(stylesXML)},
+	}
+	for i, s := range wb.sheets {
+		parts = append(parts, struct {
+			name  string
+			write func(w *bufio.Writer) error
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.write})
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(f)
+		if err := part.write(bw); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeString(s string) func(w *bufio.Writer) error {
+	return func(w *bufio.Writer) error {
+		_, err := w.WriteString(s)
+		return err
+	}
+}
+
+func (wb *Workbook) writeContentTypes(w *bufio.Writer) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	w.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	w.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	w.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	w.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range wb.sheets {
+		fmt.Fprintf(w, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	_, err := w.WriteString(`</Types>`)
+	return err
+}
+
+func (wb *Workbook) writeWorkbook(w *bufio.Writer) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range wb.sheets {
+		fmt.Fprintf(w, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.name), i+1, i+1)
+	}
+	_, err := w.WriteString(`</sheets></workbook>`)
+	return err
+}
+
+func (wb *Workbook) writeWorkbookRels(w *bufio.Writer) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.sheets {
+		fmt.Fprintf(w, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(w, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1)
+	_, err := w.WriteString(`</Relationships>`)
+	return err
+}
+
+func (s *Sheet) write(w *bufio.Writer) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.frozen {
+		w.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	if len(s.widths) > 0 {
+		w.WriteString(`<cols>`)
+		for col := 0; col <= maxKey(s.widths); col++ {
+			if width, ok := s.widths[col]; ok {
+				fmt.Fprintf(w, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, col+1, col+1, width)
+			}
+		}
+		w.WriteString(`</cols>`)
+	}
+
+	w.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(w, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			if cell.value == nil {
+				continue
+			}
+			writeCell(w, CellName(c, r), cell)
+		}
+		w.WriteString(`</row>`)
+	}
+	_, err := w.WriteString(`</sheetData></worksheet>`)
+	return err
+}
+
+func writeCell(w *bufio.Writer, ref string, c cell) {
+	style := ""
+	if c.style != styleDefault {
+		style = ` s="` + strconv.Itoa(c.style) + `"`
+	}
+
+	var number string
+	switch v := c.value.(type) {
+	case bool:
+		b := "0"
+		if v {
+			b = "1"
+		}
+		fmt.Fprintf(w, `<c r="%s" t="b"%s><v>%s</v></c>`, ref, style, b)
+		return
+	case int:
+		number = strconv.Itoa(v)
+	case int64:
+		number = strconv.FormatInt(v, 10)
+	case float64:
+		number = formatFloat(v)
+	case Percent:
+		number = formatFloat(float64(v))
+	case time.Time:
+		number = formatFloat(Serial(v))
+	case string:
+		writeText(w, ref, style, v)
+		return
+	default:
+		writeText(w, ref, style, fmt.Sprint(v))
+		return
+	}
+	if number == "" { // NaN and infinities have no cell value.
+		return
+	}
+	fmt.Fprintf(w, `<c r="%s"%s><v>%s</v></c>`, ref, style, number)
+}
+
+func writeText(w *bufio.Writer, ref, style, text string) {
+	if utf8.RuneCountInString(text) > maxCellText {
+		text = string([]rune(text)[:maxCellText])
+	}
+	fmt.Fprintf(w, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(text))
+}
+
+func formatFloat(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escape escapes text for XML, replacing characters XML cannot hold, e.g.
+// most control characters, with U+FFFD.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// excelEpoch is day 0 of serial dates, as Excel counts them since 1900
+// with a leap day that 1900 did not have.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// Serial returns t as an Excel serial date: days since excelEpoch, with the
+// time of day as the fraction. The wall clock of t is kept, as spreadsheet
+// dates have no time zone.
+func Serial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// CellName returns the A1 reference of the cell at column col and row row,
+// both from 0, e.g. CellName(27, 9) is "AB10".
+func CellName(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+func maxKey(m map[int]float64) int {
+	n := -1
+	for k := range m {
+		n = max(n, k)
+	}
+	return n
+}
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// stylesXML defines the cell styles: default, bold header, date and time
+// (yyyy-mm-dd hh:mm), and percentage.
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,129 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCellName(t *testing.T) {
+	tests := []struct {
+		col, row int
+		expected string
+	}{
+		{0, 0, "A1"},
+		{25, 1, "Z2"},
+		{26, 2, "AA3"},
+		{27, 9, "AB10"},
+		{701, 0, "ZZ1"},
+		{702, 0, "AAA1"},
+	}
+
+	for _, test := range tests {
+		if got := CellName(test.col, test.row); got != test.expected {
+			t.Errorf("CellName(%d, %d) = %q, expected %q", test.col, test.row, got, test.expected)
+		}
+	}
+}
+
+func TestSerial(t *testing.T) {
+	tests := []struct {
+		t        time.Time
+		expected float64
+	}{
+		{time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC), 61},
+		{time.Date(2024, time.January, 1, 18, 0, 0, 0, time.UTC), 45292.75},
+		// Wall clock time is kept, whatever the zone.
+		{time.Date(2024, time.January, 1, 18, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)), 45292.75},
+	}
+
+	for _, test := range tests {
+		if got := Serial(test.t); got != test.expected {
+			t.Errorf("Serial(%v) = %v, expected %v", test.t, got, test.expected)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	wb := New()
+	sheet := wb.AddSheet("Contacts")
+	sheet.AddHeader("Name", "Count", "Share", "Present", "At")
+	sheet.SetColumnWidth(0, 24)
+	sheet.AddRow("=1+1 & <b>", 3, Percent(0.5), true, time.Date(2024, time.January, 1, 18, 0, 0, 0, time.UTC))
+	sheet.AddRow(nil, 1.5, nil, false, time.Time{})
+	wb.AddSheet("Sum/mary")
+
+	var buf bytes.Buffer
+	if err := wb.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkXML(b); err != nil {
+			t.Errorf("%s: invalid xml: %v", f.Name, err)
+		}
+		parts[f.Name] = string(b)
+	}
+
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+		"xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml",
+	} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	sheet1 := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr" s="1"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">=1+1 &amp; &lt;b&gt;</t></is></c>`,
+		`<c r="B2"><v>3</v></c>`,
+		`<c r="C2" s="3"><v>0.5</v></c>`,
+		`<c r="D2" t="b"><v>1</v></c>`,
+		`<c r="E2" s="2"><v>45292.75</v></c>`,
+		`<row r="3"><c r="B3"><v>1.5</v></c><c r="D3" t="b"><v>0</v></c></row>`,
+		`state="frozen"`,
+		`<col min="1" max="1" width="24" customWidth="1"/>`,
+	} {
+		if !strings.Contains(sheet1, want) {
+			t.Errorf("sheet1.xml: missing %s", want)
+		}
+	}
+	if strings.Contains(sheet1, "<f>") {
+		t.Error("sheet1.xml: text written as a formula")
+	}
+	if want := `<sheet name="Sum-mary" sheetId="2" r:id="rId2"/>`; !strings.Contains(parts["xl/workbook.xml"], want) {
+		t.Errorf("workbook.xml: missing %s", want)
+	}
+}
+
+// checkXML reports whether b is well-formed XML.
+func checkXML(b []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
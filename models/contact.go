@@ -5,6 +5,7 @@ package models
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +19,13 @@ type (
 		Email  string    `json:"email" form:"email"`
 		Phone  string    `json:"phone" form:"phone"`
 		Status Status    `json:"status" form:"status"`
+
+		// Custom holds values of FieldDefinitions keyed by FieldDefinition.Key.
+		Custom map[string]string `json:"custom,omitempty" form:"-"`
+		// Tags group contacts by team, ticket tier, table number and the like.
+		Tags []string `json:"tags,omitempty" form:"tags"`
+		// History lists status changes, oldest first.
+		History []StatusChange `json:"history,omitempty" form:"-"`
 	}
 
 	ContactDTOS struct {
@@ -28,38 +36,135 @@ type (
 	}
 )
 
+// CustomValue returns the value of the custom field key, or "" if unset.
+func (c Contact) CustomValue(key string) string { return c.Custom[key] }
+
+// StatusChange records a contact entering a status, building up the
+// contact's attendance history.
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// LastChangeTo returns when c last entered status, if ever.
+func (c Contact) LastChangeTo(status Status) (time.Time, bool) {
+	for i := len(c.History) - 1; i >= 0; i-- {
+		if c.History[i].Status == status {
+			return c.History[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
 type Status string
 
+// Attendance statuses of a contact. See services.CanTransition for the
+// allowed changes between them.
+const (
+	StatusRegistered Status = "Registered"
+	StatusCheckedIn  Status = "CheckedIn"
+	StatusCheckedOut Status = "CheckedOut"
+	StatusNoShow     Status = "NoShow"
+	StatusCancelled  Status = "Cancelled"
+)
+
+// Legacy two-state statuses, kept for the "?active=true" and
+// "?inactive=true" count filters and older data. As filters, StatusActive
+// matches contacts that are checked in and StatusInactive every other
+// contact. Parsing them yields their Canonical status.
 const (
 	StatusActive   Status = "Active"
 	StatusInactive Status = "Inactive"
 	StatusError    Status = "Error" // Sentinel value for unexpected status
 )
 
+// Statuses lists every attendance status in lifecycle order.
+var Statuses = []Status{StatusRegistered, StatusCheckedIn, StatusCheckedOut, StatusNoShow, StatusCancelled}
+
+// InitialStatuses are the statuses a new contact may start in: registered
+// ahead of the event, or checked in as a walk-in.
+var InitialStatuses = []Status{StatusRegistered, StatusCheckedIn}
+
 var (
 	StatusActiveQueryKey   = StatusActive.QueryParam()
 	StatusInactiveQueryKey = StatusInactive.QueryParam()
 )
 
+var statusLabels = map[Status]string{
+	StatusRegistered: "Registered",
+	StatusCheckedIn:  "Checked in",
+	StatusCheckedOut: "Checked out",
+	StatusNoShow:     "No-show",
+	StatusCancelled:  "Cancelled",
+}
+
 func (s Status) String() string     { return string(s) }
-func (s Status) IsEnabled() bool    { return s == StatusActive }
+func (s Status) IsEnabled() bool    { return s == StatusActive || s == StatusCheckedIn }
 func (s Status) QueryParam() string { return strings.ToLower(s.String()) }
 
-func (s Status) CheckboxValue() (string, error) {
+// Label is the human readable name of s, e.g. "Checked in".
+func (s Status) Label() string {
+	if label, ok := statusLabels[s.Canonical()]; ok {
+		return label
+	}
+	return s.String()
+}
+
+// Canonical maps the legacy StatusActive and StatusInactive to
+// StatusCheckedIn and StatusRegistered. Other statuses map to themselves.
+func (s Status) Canonical() Status {
 	switch s {
 	case StatusActive:
-		return "on", nil
+		return StatusCheckedIn
 	case StatusInactive:
+		return StatusRegistered
+	default:
+		return s
+	}
+}
+
+// Matches reports whether a contact in status other is selected by s used as
+// a filter. See StatusActive and StatusInactive for the legacy filters.
+func (s Status) Matches(other Status) bool {
+	switch s {
+	case StatusActive:
+		return other.IsEnabled()
+	case StatusInactive:
+		return !other.IsEnabled()
+	default:
+		return s.Canonical() == other.Canonical()
+	}
+}
+
+func (s Status) CheckboxValue() (string, error) {
+	switch {
+	case s.IsEnabled():
+		return "on", nil
+	case s == StatusInactive, statusLabels[s] != "":
 		return "", nil
 	default:
 		return string(StatusError), fmt.Errorf("unexpected status: %v", s)
 	}
 }
 
+// ParseStatus parses a status name or query key case-insensitively, e.g.
+// "CheckedIn" or "checkedin". Legacy statuses parse to their Canonical status.
+func ParseStatus(s string) (Status, error) {
+	s = strings.TrimSpace(s)
+	for _, status := range append(Statuses, StatusActive, StatusInactive) {
+		if strings.EqualFold(s, status.String()) || strings.EqualFold(s, status.Label()) {
+			return status.Canonical(), nil
+		}
+	}
+	return StatusError, fmt.Errorf("unexpected status: %v", s)
+}
+
 type StatusParser struct {
 	Status Status
 }
 
+// FormCheckboxValue parses the legacy status checkbox into StatusActive or
+// StatusInactive.
 func (sh StatusParser) FormCheckboxValue(s string) (status Status, err error) {
 	switch strings.TrimSpace(s) {
 	case "on":
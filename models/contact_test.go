@@ -3,6 +3,7 @@ package models
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -137,3 +138,72 @@ func TestStatusQueryParam(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Status
+		wantErr bool
+	}{
+		{"Name", "CheckedOut", StatusCheckedOut, false},
+		{"Query key", "noshow", StatusNoShow, false},
+		{"Label", "Checked in", StatusCheckedIn, false},
+		{"Legacy active", "Active", StatusCheckedIn, false},
+		{"Legacy inactive", "inactive", StatusRegistered, false},
+		{"Unknown", "maybe", StatusError, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseStatus(test.input)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStatusMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Status
+		status Status
+		want   bool
+	}{
+		{"Same status", StatusNoShow, StatusNoShow, true},
+		{"Other status", StatusNoShow, StatusCancelled, false},
+		{"Active matches checked in", StatusActive, StatusCheckedIn, true},
+		{"Active skips checked out", StatusActive, StatusCheckedOut, false},
+		{"Inactive matches cancelled", StatusInactive, StatusCancelled, true},
+		{"Legacy data matches canonical", StatusRegistered, StatusInactive, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.Matches(test.status); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestContactLastChangeTo(t *testing.T) {
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	contact := Contact{History: []StatusChange{
+		{Status: StatusRegistered, At: first},
+		{Status: StatusCheckedIn, At: first.Add(time.Hour)},
+		{Status: StatusCheckedOut, At: first.Add(2 * time.Hour)},
+		{Status: StatusCheckedIn, At: first.Add(3 * time.Hour)},
+	}}
+
+	if got, ok := contact.LastChangeTo(StatusCheckedIn); !ok || !got.Equal(first.Add(3*time.Hour)) {
+		t.Errorf("got %v, %t, want %v", got, ok, first.Add(3*time.Hour))
+	}
+	if _, ok := contact.LastChangeTo(StatusNoShow); ok {
+		t.Errorf("got a change to %s, want none", StatusNoShow)
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+// Duplicate match reasons reported in DuplicateGroup.Reasons.
+const (
+	ReasonEmail = "email"
+	ReasonPhone = "phone"
+	ReasonName  = "name"
+)
+
+// DuplicateGroup is a set of contacts that probably describe the same person.
+type DuplicateGroup struct {
+	Contacts Contacts
+	Reasons  []string // Why members were grouped. See ReasonEmail, ReasonPhone, ReasonName.
+}
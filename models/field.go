@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType enumerates the value types of a custom contact field.
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"
+	FieldNumber  FieldType = "number"
+	FieldSelect  FieldType = "select"
+	FieldBoolean FieldType = "boolean"
+	FieldDate    FieldType = "date"
+)
+
+// FieldTypes lists every FieldType in the order shown to admins.
+var FieldTypes = []FieldType{FieldText, FieldNumber, FieldSelect, FieldBoolean, FieldDate}
+
+// FieldDateLayout is the layout of FieldDate values, matching <input type="date">.
+const FieldDateLayout = "2006-01-02"
+
+// FieldDefinition describes a custom contact field defined per deployment,
+// e.g. a company name or a T-shirt size. Values live in Contact.Custom keyed
+// by Key.
+type FieldDefinition struct {
+	Key      string    `json:"key"`
+	Label    string    `json:"label"`
+	Type     FieldType `json:"type"`
+	Required bool      `json:"required,omitempty"`
+	Options  []string  `json:"options,omitempty"` // Allowed values of a FieldSelect.
+}
+
+// FieldDefinitions is an ordered list of custom fields.
+type FieldDefinitions []FieldDefinition
+
+var fieldKeyRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+var (
+	ErrFieldRequired error = errors.New("value is required")
+	ErrFieldOption   error = errors.New("value is not one of the allowed options")
+)
+
+// Errors of FieldDefinition.Check, by the part of the definition at fault.
+var (
+	ErrFieldKey     error = errors.New("invalid field key")
+	ErrFieldLabel   error = errors.New("label is required")
+	ErrFieldType    error = errors.New("unknown type")
+	ErrFieldOptions error = errors.New("select requires at least one option")
+)
+
+// FormName is the name of the form input holding the field's value.
+func (fd FieldDefinition) FormName() string { return "custom_" + fd.Key }
+
+// Check reports whether the definition itself is well formed.
+func (fd FieldDefinition) Check() error {
+	if !fieldKeyRegexp.MatchString(fd.Key) {
+		return fmt.Errorf("%w %q: use lowercase letters, digits and underscores", ErrFieldKey, fd.Key)
+	}
+	if strings.TrimSpace(fd.Label) == "" {
+		return fmt.Errorf("field %q: %w", fd.Key, ErrFieldLabel)
+	}
+
+	switch fd.Type {
+	case FieldText, FieldNumber, FieldBoolean, FieldDate:
+	case FieldSelect:
+		if len(fd.Options) == 0 {
+			return fmt.Errorf("field %q: %w", fd.Key, ErrFieldOptions)
+		}
+	default:
+		return fmt.Errorf("field %q: %w %q", fd.Key, ErrFieldType, fd.Type)
+	}
+
+	return nil
+}
+
+// Normalize validates a raw value of the field and returns its canonical form.
+//
+// Booleans normalize to "true" or "false", accepting checkbox "on" values;
+// everything else is trimmed. An empty optional value is valid.
+func (fd FieldDefinition) Normalize(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+
+	if fd.Type == FieldBoolean {
+		switch strings.ToLower(value) {
+		case "true", "on", "yes", "1":
+			value = "true"
+		case "", "false", "off", "no", "0":
+			value = "false"
+		default:
+			return "", fmt.Errorf("invalid boolean %q", raw)
+		}
+
+		if fd.Required && value != "true" {
+			return "", ErrFieldRequired
+		}
+		return value, nil
+	}
+
+	if value == "" {
+		if fd.Required {
+			return "", ErrFieldRequired
+		}
+		return "", nil
+	}
+
+	switch fd.Type {
+	case FieldNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("invalid number %q", raw)
+		}
+	case FieldSelect:
+		for _, option := range fd.Options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+		return "", ErrFieldOption
+	case FieldDate:
+		if _, err := time.Parse(FieldDateLayout, value); err != nil {
+			return "", fmt.Errorf("invalid date %q: use YYYY-MM-DD", raw)
+		}
+	}
+
+	return value, nil
+}
+
+// Lookup returns the definition with key.
+func (fds FieldDefinitions) Lookup(key string) (FieldDefinition, bool) {
+	for _, fd := range fds {
+		if fd.Key == key {
+			return fd, true
+		}
+	}
+	return FieldDefinition{}, false
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFieldDefinitionCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		fd   FieldDefinition
+		want error
+	}{
+		{"text", FieldDefinition{Key: "company", Label: "Company", Type: FieldText}, nil},
+		{"select", FieldDefinition{Key: "tshirt", Label: "T-shirt", Type: FieldSelect, Options: []string{"S", "M"}}, nil},
+		{"select without options", FieldDefinition{Key: "tshirt", Label: "T-shirt", Type: FieldSelect}, ErrFieldOptions},
+		{"bad key", FieldDefinition{Key: "T-shirt", Label: "T-shirt", Type: FieldText}, ErrFieldKey},
+		{"missing label", FieldDefinition{Key: "company", Type: FieldText}, ErrFieldLabel},
+		{"unknown type", FieldDefinition{Key: "company", Label: "Company", Type: "color"}, ErrFieldType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fd.Check(); !errors.Is(err, tt.want) {
+				t.Errorf("Check() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldDefinitionNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		fd      FieldDefinition
+		raw     string
+		want    string
+		wantErr error
+	}{
+		{"text", FieldDefinition{Type: FieldText}, "  Acme ", "Acme", nil},
+		{"optional empty", FieldDefinition{Type: FieldText}, "", "", nil},
+		{"required empty", FieldDefinition{Type: FieldText, Required: true}, " ", "", ErrFieldRequired},
+		{"number", FieldDefinition{Type: FieldNumber}, "12.5", "12.5", nil},
+		{"select case", FieldDefinition{Type: FieldSelect, Options: []string{"S", "M"}}, "m", "M", nil},
+		{"select unknown", FieldDefinition{Type: FieldSelect, Options: []string{"S", "M"}}, "XL", "", ErrFieldOption},
+		{"boolean checkbox", FieldDefinition{Type: FieldBoolean}, "on", "true", nil},
+		{"boolean unchecked", FieldDefinition{Type: FieldBoolean}, "", "false", nil},
+		{"boolean required", FieldDefinition{Type: FieldBoolean, Required: true}, "", "", ErrFieldRequired},
+		{"date", FieldDefinition{Type: FieldDate}, "2024-02-29", "2024-02-29", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fd.Normalize(tt.raw)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Normalize(%q) unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+
+	for _, raw := range []string{"abc", "1,5"} {
+		if _, err := (FieldDefinition{Type: FieldNumber}).Normalize(raw); err == nil {
+			t.Errorf("Normalize(%q) as number should fail", raw)
+		}
+	}
+	if _, err := (FieldDefinition{Type: FieldDate}).Normalize("29/02/2024"); err == nil {
+		t.Errorf("Normalize of non ISO date should fail")
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+)
+
+// TagCount holds per-tag contact counts.
+type TagCount struct {
+	Tag      string
+	Total    int
+	Active   int
+	Inactive int
+}
+
+// ContactFilter selects contacts. Zero fields match everything.
+type ContactFilter struct {
+	Tag    string
+	Status Status
+}
+
+// Match reports whether c is selected by f.
+func (f ContactFilter) Match(c Contact) bool {
+	if f.Tag != "" && !c.HasTag(f.Tag) {
+		return false
+	}
+	if f.Status != "" && !f.Status.Matches(c.Status) {
+		return false
+	}
+	return true
+}
+
+// NormalizeTag trims a tag and collapses inner whitespace, e.g. " Table  4 "
+// becomes "Table 4". Tags compare case-insensitively.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// ParseTags splits a comma separated list of tags, dropping blanks and
+// case-insensitive duplicates.
+func ParseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		tags = AddTag(tags, tag)
+	}
+	return tags
+}
+
+// AddTag appends tag to tags unless it is blank or already present.
+func AddTag(tags []string, tag string) []string {
+	if tag = NormalizeTag(tag); tag == "" || containsTag(tags, tag) {
+		return tags
+	}
+	return append(tags, tag)
+}
+
+// RemoveTag returns tags without tag.
+func RemoveTag(tags []string, tag string) []string {
+	out := tags[:0:0]
+	for _, t := range tags {
+		if !strings.EqualFold(t, NormalizeTag(tag)) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// HasTag reports whether c is tagged with tag, ignoring case.
+func (c Contact) HasTag(tag string) bool { return containsTag(c.Tags, NormalizeTag(tag)) }
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"vip, Table  4 ,, vip ,VIP", []string{"vip", "Table 4"}},
+		{"", nil},
+		{" , ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTags(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTags(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddRemoveTag(t *testing.T) {
+	tags := AddTag(nil, "Speaker")
+	tags = AddTag(tags, "speaker")
+	tags = AddTag(tags, "Staff")
+
+	if want := []string{"Speaker", "Staff"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("got %q, want %q", tags, want)
+	}
+
+	tags = RemoveTag(tags, "SPEAKER")
+	if want := []string{"Staff"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %q, want %q", tags, want)
+	}
+}
+
+func TestContactFilterMatch(t *testing.T) {
+	contact := Contact{Status: StatusActive, Tags: []string{"VIP"}}
+
+	tests := []struct {
+		name   string
+		filter ContactFilter
+		want   bool
+	}{
+		{"empty", ContactFilter{}, true},
+		{"tag", ContactFilter{Tag: "vip"}, true},
+		{"other tag", ContactFilter{Tag: "staff"}, false},
+		{"tag and status", ContactFilter{Tag: "VIP", Status: StatusActive}, true},
+		{"tag and other status", ContactFilter{Tag: "VIP", Status: StatusInactive}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(contact); got != tt.want {
+				t.Errorf("Match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// FieldErrors maps a form field name, e.g. "email", to its validation messages.
+//
+// A nil FieldErrors can be read, reporting no errors, but Add needs one made
+// with make or a literal, e.g. models.FieldErrors{}.
+type FieldErrors map[string][]string
+
+// Add appends message to the messages of field. It panics on a nil
+// FieldErrors, like any write to a nil map.
+func (fe FieldErrors) Add(field, message string) {
+	fe[field] = append(fe[field], message)
+}
+
+// Get returns the messages of field, if any.
+func (fe FieldErrors) Get(field string) []string { return fe[field] }
+
+// Has reports whether field has at least one message.
+func (fe FieldErrors) Has(field string) bool { return len(fe[field]) > 0 }
+
+// Any reports whether any field has a message.
+func (fe FieldErrors) Any() bool { return len(fe) > 0 }
+
+// Error implements error so that FieldErrors can be returned and logged like
+// any other error. Fields are sorted for stable output.
+func (fe FieldErrors) Error() string {
+	fields := make([]string, 0, len(fe))
+	for field := range fe {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(field + ": " + strings.Join(fe[field], ", "))
+	}
+
+	return b.String()
+}
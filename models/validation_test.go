@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestFieldErrors(t *testing.T) {
+	var empty FieldErrors
+	if empty.Any() || empty.Has("email") {
+		t.Errorf("nil FieldErrors should report no errors")
+	}
+
+	errs := FieldErrors{}
+	errs.Add("phone", "phone number is too short")
+	errs.Add("email", "invalid email format")
+	errs.Add("email", "email is required")
+
+	if !errs.Any() || !errs.Has("email") || errs.Has("name") {
+		t.Errorf("unexpected Any/Has results for %v", errs)
+	}
+
+	if got := len(errs.Get("email")); got != 2 {
+		t.Errorf("got %d email messages, want 2", got)
+	}
+
+	want := "email: invalid email format, email is required; phone: phone number is too short"
+	if got := errs.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -18,18 +18,6 @@ import (
 	"github.com/lloydlobo/go-headcount/models"
 )
 
-// Hack: bypass linter warning for unused function
-func TmpInit() {
-	cs := NewContactServiceFromAPI()
-	cs.updateContactCountCache()
-	go func() {
-		for {
-			time.Sleep(time.Minute)
-			cs.updateContactCountCache()
-		}
-	}()
-}
-
 // Action implements enumeration of actions.
 type Action int
 
@@ -48,21 +36,21 @@ var (
 	ErrUnknownAction error = errors.New("unknown action type")
 )
 
-func NewContactService() *ContactService {
+func NewContactService(cfg internal.Config) *ContactService {
 	return &ContactService{
 		Contacts: models.Contacts{},
 		seq:      1,
+		config:   cfg,
 	}
 }
 
-func NewContactServiceFromAPI() *ContactService {
-	apiURL := internal.LookupEnv("API_URL", "https://jsonplaceholder.typicode.com/users")
-
+// NewContactServiceFromAPI seeds the contacts with the users at cfg.ApiUrl.
+func NewContactServiceFromAPI(cfg internal.Config) *ContactService {
 	// Note: Using context.Background() is not idiomatic. But still using it before the http server is started.
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel() // cancel context when done fetching
 
-	contacts, err := fetchUsers(ctx, apiURL)
+	contacts, err := fetchUsers(ctx, cfg.ApiUrl, cfg.PhoneRegion)
 	if err != nil {
 		log.Fatalf("failed to fetch and transform users from api: %v", err)
 	}
@@ -70,15 +58,18 @@ func NewContactServiceFromAPI() *ContactService {
 	return &ContactService{
 		Contacts: contacts,
 		seq:      1,
+		config:   cfg,
 	}
 }
 
 type ContactService struct {
 	lock              sync.Mutex // Lock and defer Unlock during mutation of contacts.
 	Contacts          models.Contacts
-	seq               int // Tracks times contact is created while server is running. Start from 1.
-	idCounter         int // Tracks current count of Contact till when session resets. Start from 0.
+	fields            models.FieldDefinitions // Custom fields defined for this deployment.
+	seq               int                     // Tracks times contact is created while server is running. Start from 1.
+	idCounter         int                     // Tracks current count of Contact till when session resets. Start from 0.
 	ContactCountCache *int64
+	config            internal.Config
 }
 
 func (cs *ContactService) Get() (models.Contacts, error) {
@@ -111,14 +102,15 @@ func (cs *ContactService) CrudOps(action Action, contact models.Contact) models.
 	if action != ActionCreate {
 		index = cs.findIndexByID(contact.ID)
 
-		if index == -1 && action == ActionEdit {
+		if index == -1 && (action == ActionEdit || action == ActionToggle || action == ActionUpdate) {
 			log.Println("error: index is -1", contact)
-			return contact
+			return models.Contact{}
 		}
 	}
 
 	switch action {
 	case ActionCreate:
+		recordStatus(&contact, contact.Status, time.Now())
 		cs.Contacts = append(cs.Contacts, contact)
 		cs.idCounter++
 		cs.seq++
@@ -126,8 +118,12 @@ func (cs *ContactService) CrudOps(action Action, contact models.Contact) models.
 		return contact
 
 	case ActionToggle:
-		cs.Contacts[index].Status = contact.Status
-		return contact
+		if err := CheckTransition(cs.Contacts[index].Status, contact.Status); err != nil {
+			log.Printf("failed to toggle status: %v", err)
+			return cs.Contacts[index]
+		}
+		recordStatus(&cs.Contacts[index], contact.Status, time.Now())
+		return cs.Contacts[index]
 
 	case ActionUpdate:
 		name := strings.TrimSpace(contact.Name)
@@ -137,14 +133,27 @@ func (cs *ContactService) CrudOps(action Action, contact models.Contact) models.
 			log.Printf("failed to validate email: %v", err)
 			return models.Contact{} //, fmt.Errorf("error while validating email: %v", err)
 		}
+		if phone != "" {
+			var err error
+			if phone, err = internal.NormalizePhone(phone, cs.config.PhoneRegion); err != nil {
+				log.Printf("failed to validate phone: %v", err)
+				return models.Contact{}
+			}
+		}
 		status := contact.Status
+		if err := CheckTransition(cs.Contacts[index].Status, status); err != nil {
+			log.Printf("failed to update status: %v", err)
+			return models.Contact{}
+		}
 
 		if name != "" && phone != "" && email != "" {
 			cs.Contacts[index].Name = name
 			cs.Contacts[index].Email = email
 			cs.Contacts[index].Phone = phone
-			cs.Contacts[index].Status = status
-			return contact
+			cs.Contacts[index].Custom = contact.Custom
+			cs.Contacts[index].Tags = contact.Tags
+			recordStatus(&cs.Contacts[index], status, time.Now())
+			return cs.Contacts[index]
 		}
 		// otherwise remove if name is empty
 		cs.deleteContact(index)
@@ -177,7 +186,7 @@ func (cs *ContactService) CountByStatus(s models.Status) (count int) {
 
 	count = 0
 	for _, c := range cs.Contacts {
-		if c.Status == s {
+		if s.Matches(c.Status) {
 			count++
 		}
 	}
@@ -204,7 +213,7 @@ func (cs *ContactService) deleteContact(index int) {
 	}
 }
 
-func fetchUsers(ctx context.Context, apiURL string) (models.Contacts, error) {
+func fetchUsers(ctx context.Context, apiURL, phoneRegion string) (models.Contacts, error) {
 	client := &http.Client{Timeout: 5 * time.Second}
 	maxRetries := 3
 	delay := 1 * time.Second // use exponential backoff for retries instead of fixed count
@@ -247,14 +256,22 @@ func fetchUsers(ctx context.Context, apiURL string) (models.Contacts, error) {
 			return contacts, fmt.Errorf("error decoding fetched user data from api: %v", err)
 		}
 
+		now := time.Now()
 		for _, c := range contactsRaw {
-			contacts = append(contacts, models.Contact{
-				ID:     uuid.New(),
-				Name:   c.Name,
-				Email:  c.Email,
-				Phone:  c.Phone,
-				Status: models.StatusInactive,
-			})
+			phone, err := internal.NormalizePhone(c.Phone, phoneRegion)
+			if err != nil {
+				log.Printf("keeping raw phone %q of user %d: %v", c.Phone, c.ID, err)
+				phone = c.Phone
+			}
+
+			contact := models.Contact{
+				ID:    uuid.New(),
+				Name:  c.Name,
+				Email: c.Email,
+				Phone: phone,
+			}
+			recordStatus(&contact, models.StatusRegistered, now)
+			contacts = append(contacts, contact)
 		}
 
 		return contacts, nil // breaks retries loop
@@ -263,6 +280,23 @@ func fetchUsers(ctx context.Context, apiURL string) (models.Contacts, error) {
 	return nil, errors.New("failed to fetch user data after all retries")
 }
 
+// RefreshCountCache updates ContactCountCache now, then every interval
+// until ctx is done.
+func (cs *ContactService) RefreshCountCache(ctx context.Context, interval time.Duration) {
+	cs.updateContactCountCache()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cs.updateContactCountCache()
+		}
+	}
+}
+
 func (cs *ContactService) updateContactCountCache() {
 	// # Usage
 	//
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// csvColumns are the built-in columns of a roster CSV. Custom fields follow,
+// headed by their FieldDefinition.Key.
+var csvColumns = []string{"id", "name", "email", "phone", "status", "tags"}
+
+// WriteCSV writes contacts as CSV with a header row of csvColumns followed by
+// one column per custom field.
+func WriteCSV(w io.Writer, contacts models.Contacts, fields models.FieldDefinitions) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string(nil), csvColumns...)
+	for _, fd := range fields {
+		header = append(header, fd.Key)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range contacts {
+		record := []string{c.ID.String(), c.Name, c.Email, c.Phone, c.Status.String(), strings.Join(c.Tags, ", ")}
+		for _, fd := range fields {
+			record = append(record, c.CustomValue(fd.Key))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads contacts from CSV written by WriteCSV or a spreadsheet.
+//
+// Columns are matched by header name case-insensitively, custom fields by
+// key or label, and unknown columns are ignored. Missing ids are generated,
+// phones are normalized, as numbers of phoneRegion when they have no country
+// code, and every row is validated. If any row is invalid
+// no contacts are returned, and the error lists every invalid row.
+func ReadCSV(r io.Reader, fields models.FieldDefinitions, phoneRegion string) (models.Contacts, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %v", err)
+	}
+
+	column := make(map[string]int)
+	custom := make(map[int]models.FieldDefinition)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if internal.Contains(csvColumns, name) {
+			column[name] = i
+			continue
+		}
+		for _, fd := range fields {
+			if name == fd.Key || name == strings.ToLower(fd.Label) {
+				custom[i] = fd
+			}
+		}
+	}
+
+	for _, required := range []string{"name", "email"} {
+		if _, ok := column[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", required)
+		}
+	}
+
+	var (
+		contacts models.Contacts
+		errs     []error
+		ids      = idSet{}
+	)
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, fmt.Errorf("line %d: %v", parseErr.Line, parseErr.Err))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		line, _ := cr.FieldPos(0) // Only valid for rows that parsed.
+
+		get := func(name string) string {
+			if i, ok := column[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		values := make(map[string]string)
+		for i, fd := range custom {
+			if i < len(record) {
+				values[fd.Key] = record[i]
+			}
+		}
+
+		contact, fieldErrs := contactFromRecord(get, values, fields, phoneRegion)
+		if fieldErrs.Any() {
+			errs = append(errs, fmt.Errorf("line %d: %v", line, fieldErrs))
+			continue
+		}
+		if ids.seen(contact.ID) {
+			errs = append(errs, fmt.Errorf("line %d: duplicate id %s", line, contact.ID))
+			continue
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return contacts, nil
+}
+
+// idSet holds the ids of the contacts read from a file, so that a file
+// listing a contact twice is rejected rather than importing both copies.
+type idSet map[uuid.UUID]bool
+
+// seen adds id to s, reporting whether it was already there.
+func (s idSet) seen(id uuid.UUID) bool {
+	if s[id] {
+		return true
+	}
+	s[id] = true
+	return false
+}
+
+// contactFromRecord validates a contact read by column name with get, and
+// custom field values keyed by FieldDefinition.Key.
+func contactFromRecord(get func(string) string, values map[string]string, fields models.FieldDefinitions, phoneRegion string) (models.Contact, models.FieldErrors) {
+	errs := models.FieldErrors{}
+
+	contact := models.Contact{
+		ID:     uuid.New(),
+		Name:   get("name"),
+		Email:  get("email"),
+		Status: models.StatusRegistered,
+		Tags:   models.ParseTags(get("tags")),
+	}
+
+	if id := get("id"); id != "" {
+		uuidID, err := uuid.Parse(id)
+		if err != nil {
+			errs.Add("id", err.Error())
+		}
+		contact.ID = uuidID
+	}
+
+	if contact.Name == "" {
+		errs.Add("name", "name is required")
+	}
+
+	if err := internal.ValidateEmail(contact.Email); err != nil || contact.Email == "" {
+		errs.Add("email", fmt.Sprintf("invalid email %q", contact.Email))
+	}
+
+	if phone := get("phone"); phone != "" {
+		normalized, err := internal.NormalizePhone(phone, phoneRegion)
+		if err != nil {
+			errs.Add("phone", err.Error())
+		}
+		contact.Phone = normalized
+	}
+
+	if status := get("status"); status != "" {
+		parsed, err := models.ParseStatus(status)
+		if err != nil {
+			errs.Add("status", err.Error())
+		}
+		contact.Status = parsed
+	}
+
+	for _, fd := range fields {
+		value, err := fd.Normalize(values[fd.Key])
+		if err != nil {
+			errs.Add(fd.Key, err.Error())
+			continue
+		}
+		if value != "" {
+			if contact.Custom == nil {
+				contact.Custom = make(map[string]string)
+			}
+			contact.Custom[fd.Key] = value
+		}
+	}
+
+	return contact, errs
+}
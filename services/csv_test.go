@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestReadCSV(t *testing.T) {
+	id := uuid.New().String()
+	tests := []struct {
+		name    string
+		csv     string
+		want    int
+		wantErr string
+	}{
+		{"new contacts", "name,email\nAda,ada@example.com\nAlan,alan@example.com\n", 2, ""},
+		{"missing column", "name\nAda\n", 0, `missing column "email"`},
+		{"invalid row", "name,email\nAda,ada@example.com\n,nobody\n", 0, "line 3"},
+		{"bare quote", "name,email\nA,a@example.com\n\"bad\"x,y\n", 0, `line 3: extraneous or missing "`},
+		{"duplicate id", "id,name,email\n" + id + ",Ada,ada@example.com\n" + id + ",Ada King,ada@example.com\n", 0, "line 3: duplicate id " + id},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contacts, err := ReadCSV(strings.NewReader(test.csv), nil, "US")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got error %v, want %q", err, test.wantErr)
+				}
+				if contacts != nil {
+					t.Errorf("got %d contacts, want none", len(contacts))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(contacts) != test.want {
+				t.Errorf("got %d contacts, want %d", len(contacts), test.want)
+			}
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	contacts := models.Contacts{
+		{ID: uuid.New(), Name: "Ada Lovelace", Email: "ada@example.com", Phone: "+12025550123", Status: models.StatusCheckedIn, Tags: []string{"R&D", "vip"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, contacts, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCSV(&buf, nil, "US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != contacts[0].ID || got[0].Name != contacts[0].Name || got[0].Status != contacts[0].Status {
+		t.Errorf("got %+v, want %+v", got, contacts)
+	}
+}
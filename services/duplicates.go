@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// NameSimilarityThreshold is the minimum internal.NameSimilarity for two
+// contacts to be reported as duplicates by name alone.
+const NameSimilarityThreshold = 0.92
+
+// MergeFields lists the columns a merge can pick values for.
+var MergeFields = []string{"name", "email", "phone", "status"}
+
+var (
+	ErrMergeTooFew    error = errors.New("merge requires at least two contacts")
+	ErrMergeNotFound  error = errors.New("contact to merge not found")
+	ErrMergeBadKeeper error = errors.New("contact to keep must be one of the merged contacts")
+)
+
+// MergeRequest describes how to collapse a DuplicateGroup into one contact.
+type MergeRequest struct {
+	KeepID uuid.UUID   // Contact that survives the merge.
+	IDs    []uuid.UUID // Every contact taking part in the merge, including KeepID.
+
+	// Picks maps a field in MergeFields to the contact whose value is kept.
+	// Fields without a pick keep the value of KeepID.
+	Picks map[string]uuid.UUID
+}
+
+// FindDuplicates groups contacts sharing a normalized email, a normalized
+// phone number, or a name at least NameSimilarityThreshold alike.
+//
+// Matches are transitive: if A shares an email with B and B shares a phone
+// with C, all three end up in the same group.
+func (cs *ContactService) FindDuplicates() []models.DuplicateGroup {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	n := len(cs.Contacts)
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	reasons := make(map[int]map[string]bool) // Keyed by pair member, merged by root below.
+	union := func(i, j int, reason string) {
+		for _, k := range []int{i, j} {
+			if reasons[k] == nil {
+				reasons[k] = make(map[string]bool)
+			}
+			reasons[k][reason] = true
+		}
+		parent[find(i)] = find(j)
+	}
+
+	byEmail := make(map[string]int)
+	byPhone := make(map[string]int)
+	for i, c := range cs.Contacts {
+		if email := internal.NormalizeEmail(c.Email); email != "" {
+			if j, ok := byEmail[email]; ok {
+				union(i, j, models.ReasonEmail)
+			} else {
+				byEmail[email] = i
+			}
+		}
+		if phone := phoneKey(c.Phone, cs.config.PhoneRegion); phone != "" {
+			if j, ok := byPhone[phone]; ok {
+				union(i, j, models.ReasonPhone)
+			} else {
+				byPhone[phone] = i
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if internal.NameSimilarity(cs.Contacts[i].Name, cs.Contacts[j].Name) >= NameSimilarityThreshold {
+				union(i, j, models.ReasonName)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int // Keeps groups in roster order.
+	for i := 0; i < n; i++ {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	var groups []models.DuplicateGroup
+	for _, root := range roots {
+		if len(members[root]) < 2 {
+			continue
+		}
+
+		var group models.DuplicateGroup
+		seen := make(map[string]bool)
+		for _, i := range members[root] {
+			group.Contacts = append(group.Contacts, cs.Contacts[i])
+			for _, reason := range []string{models.ReasonEmail, models.ReasonPhone, models.ReasonName} {
+				if reasons[i][reason] && !seen[reason] {
+					seen[reason] = true
+					group.Reasons = append(group.Reasons, reason)
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// Merge collapses the contacts in req.IDs into req.KeepID, taking each field
+// from the contact chosen in req.Picks, and deletes the other contacts.
+// Custom field values, tags and attendance history of all contacts are kept.
+// IDs listed twice are merged once. A picked status must be one the kept
+// contact may change to, see CheckTransition.
+//
+// The whole merge happens under one lock so readers never observe a roster
+// with the merged contact and its losers side by side.
+func (cs *ContactService) Merge(req MergeRequest) (models.Contact, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	byID := make(map[uuid.UUID]models.Contact, len(req.IDs))
+	ids := make([]uuid.UUID, 0, len(req.IDs)) // req.IDs without repeats, in order.
+	for _, id := range req.IDs {
+		if _, ok := byID[id]; ok {
+			continue
+		}
+		index := cs.findIndexByID(id)
+		if index == -1 {
+			return models.Contact{}, fmt.Errorf("%w: %s", ErrMergeNotFound, id)
+		}
+		byID[id] = cs.Contacts[index]
+		ids = append(ids, id)
+	}
+
+	if len(byID) < 2 {
+		return models.Contact{}, ErrMergeTooFew
+	}
+
+	merged, ok := byID[req.KeepID]
+	if !ok {
+		return models.Contact{}, ErrMergeBadKeeper
+	}
+
+	for field, id := range req.Picks {
+		source, ok := byID[id]
+		if !ok {
+			return models.Contact{}, fmt.Errorf("%w: %s picked for %s", ErrMergeNotFound, id, field)
+		}
+
+		switch field {
+		case "name":
+			merged.Name = source.Name
+		case "email":
+			merged.Email = source.Email
+		case "phone":
+			merged.Phone = source.Phone
+		case "status":
+			merged.Status = source.Status
+		default:
+			return models.Contact{}, fmt.Errorf("unknown merge field: %s", field)
+		}
+	}
+	if err := CheckTransition(byID[req.KeepID].Status, merged.Status); err != nil {
+		return models.Contact{}, err
+	}
+
+	// Custom field values of the kept contact win; blanks are filled from the others.
+	custom := make(map[string]string)
+	for _, id := range ids {
+		for key, value := range byID[id].Custom {
+			if _, ok := custom[key]; !ok || id == req.KeepID {
+				custom[key] = value
+			}
+		}
+	}
+	if len(custom) > 0 {
+		merged.Custom = custom
+	}
+
+	var tags []string
+	for _, id := range ids {
+		for _, tag := range byID[id].Tags {
+			tags = models.AddTag(tags, tag)
+		}
+	}
+	merged.Tags = tags
+
+	// Attendance history is the union of every merged contact's history.
+	var history []models.StatusChange
+	for _, id := range ids {
+		history = append(history, byID[id].History...)
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].At.Before(history[j].At) })
+	merged.History = history
+
+	cs.Contacts[cs.findIndexByID(req.KeepID)] = merged
+
+	for id := range byID {
+		if id != req.KeepID {
+			cs.deleteContact(cs.findIndexByID(id))
+		}
+	}
+
+	return merged, nil
+}
+
+// phoneKey returns the E.164 form of phone, ignoring any extension, so that
+// differently formatted numbers match. Unparseable numbers fall back to their
+// digits. Numbers without a country code are read as numbers of region.
+func phoneKey(phone, region string) string {
+	if p, err := internal.ParsePhone(phone, region); err == nil {
+		return p.E164()
+	}
+	return internal.NormalizeDigits(phone)
+}
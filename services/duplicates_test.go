@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestMerge(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		keepStatus     models.Status
+		otherStatus    models.Status
+		ids            []uuid.UUID
+		pickStatus     bool
+		wantErr        error
+		wantStatus     models.Status
+		wantHistoryLen int
+	}{
+		{"Repeated ids", models.StatusRegistered, models.StatusCheckedIn, []uuid.UUID{a, b, b, a}, false, nil, models.StatusRegistered, 2},
+		{"Picked status allowed", models.StatusRegistered, models.StatusCheckedIn, []uuid.UUID{a, b}, true, nil, models.StatusCheckedIn, 2},
+		{"Picked status not allowed", models.StatusCancelled, models.StatusCheckedIn, []uuid.UUID{a, b}, true, ErrInvalidTransition, "", 0},
+		{"Only repeats of one id", models.StatusRegistered, models.StatusCheckedIn, []uuid.UUID{a, a}, false, ErrMergeTooFew, "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs := NewContactService(internal.DefaultConfig())
+			cs.Import(models.Contacts{
+				{ID: a, Name: "Ada Lovelace", Email: "ada@example.com", Status: test.keepStatus},
+				{ID: b, Name: "Ada King", Email: "ada@example.com", Status: test.otherStatus},
+			})
+
+			req := MergeRequest{KeepID: a, IDs: test.ids}
+			if test.pickStatus {
+				req.Picks = map[string]uuid.UUID{"status": b}
+			}
+			merged, err := cs.Merge(req)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if err != nil {
+				if cs.Count() != 2 {
+					t.Errorf("got %d contacts after a failed merge, want 2", cs.Count())
+				}
+				return
+			}
+
+			if cs.Count() != 1 {
+				t.Errorf("got %d contacts, want 1", cs.Count())
+			}
+			if merged.Status != test.wantStatus {
+				t.Errorf("status: got %s, want %s", merged.Status, test.wantStatus)
+			}
+			if len(merged.History) != test.wantHistoryLen {
+				t.Errorf("history: got %v, want %d changes", merged.History, test.wantHistoryLen)
+			}
+		})
+	}
+}
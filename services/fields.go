@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+var (
+	ErrFieldExists   error = errors.New("custom field already exists")
+	ErrFieldNotFound error = errors.New("custom field not found")
+)
+
+// LoadFieldDefinitions reads custom field definitions from a JSON file holding
+// an array of models.FieldDefinition, e.g.
+//
+//	[
+//		{"key": "company", "label": "Company", "type": "text"},
+//		{"key": "tshirt", "label": "T-shirt size", "type": "select", "options": ["S", "M", "L", "XL"]}
+//	]
+func LoadFieldDefinitions(path string) (models.FieldDefinitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading custom fields: %v", err)
+	}
+
+	var fields models.FieldDefinitions
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error decoding custom fields from %s: %v", path, err)
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for _, fd := range fields {
+		if err := fd.Check(); err != nil {
+			return nil, err
+		}
+		if seen[fd.Key] {
+			return nil, fmt.Errorf("%w: %s", ErrFieldExists, fd.Key)
+		}
+		seen[fd.Key] = true
+	}
+
+	return fields, nil
+}
+
+// Fields returns the custom fields defined for this deployment.
+func (cs *ContactService) Fields() models.FieldDefinitions {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	return append(models.FieldDefinitions(nil), cs.fields...)
+}
+
+// SetFields replaces every custom field definition, e.g. with the result of
+// LoadFieldDefinitions at startup. Existing contact values are kept.
+func (cs *ContactService) SetFields(fields models.FieldDefinitions) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	cs.fields = append(models.FieldDefinitions(nil), fields...)
+}
+
+// DefineField appends a new custom field definition.
+func (cs *ContactService) DefineField(fd models.FieldDefinition) error {
+	if err := fd.Check(); err != nil {
+		return err
+	}
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	if _, ok := cs.fields.Lookup(fd.Key); ok {
+		return fmt.Errorf("%w: %s", ErrFieldExists, fd.Key)
+	}
+
+	cs.fields = append(cs.fields, fd)
+	return nil
+}
+
+// RemoveField deletes the custom field key and its value from every contact.
+func (cs *ContactService) RemoveField(key string) error {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	for i, fd := range cs.fields {
+		if fd.Key != key {
+			continue
+		}
+
+		cs.fields = append(cs.fields[:i], cs.fields[i+1:]...)
+		for j := range cs.Contacts {
+			delete(cs.Contacts[j].Custom, key)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrFieldNotFound, key)
+}
+
+// Import adds contacts to the roster under a single lock. A contact whose ID
+// is already on the roster replaces it in place, e.g. when an edited export
+// is imported back, keeping its status history unless it has its own.
+func (cs *ContactService) Import(contacts models.Contacts) (added, updated int) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	now := time.Now()
+	for _, c := range contacts {
+		if i := cs.findIndexByID(c.ID); i != -1 {
+			if len(c.History) == 0 {
+				c.History = cs.Contacts[i].History
+				recordStatus(&c, c.Status, now)
+			}
+			cs.Contacts[i] = c
+			updated++
+			continue
+		}
+
+		if len(c.History) == 0 {
+			recordStatus(&c, c.Status, now)
+		}
+		cs.Contacts = append(cs.Contacts, c)
+		added++
+	}
+	cs.idCounter += added
+	cs.seq += added
+	return added, updated
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestImport(t *testing.T) {
+	cs := NewContactService(internal.DefaultConfig())
+	existing := models.Contact{ID: uuid.New(), Name: "Ada Lovelace", Email: "ada@example.com", Status: models.StatusRegistered}
+	if added, updated := cs.Import(models.Contacts{existing}); added != 1 || updated != 0 {
+		t.Fatalf("got %d added, %d updated, expected 1 added", added, updated)
+	}
+
+	// Re-importing an edited export updates the contact in place.
+	edited := existing
+	edited.Name = "Ada King"
+	edited.Status = models.StatusCheckedIn
+	added, updated := cs.Import(models.Contacts{edited, {ID: uuid.New(), Name: "Alan Turing", Email: "alan@example.com"}})
+	if added != 1 || updated != 1 {
+		t.Errorf("got %d added, %d updated, expected 1 of each", added, updated)
+	}
+	if cs.Count() != 2 {
+		t.Fatalf("got %d contacts, expected 2", cs.Count())
+	}
+
+	got := cs.Contacts[0]
+	if got.ID != existing.ID || got.Name != "Ada King" || got.Status != models.StatusCheckedIn {
+		t.Errorf("got %+v, expected the edited contact in place", got)
+	}
+	if len(got.History) != 2 || got.History[0].Status != models.StatusRegistered || got.History[1].Status != models.StatusCheckedIn {
+		t.Errorf("got history %v, expected registered then checked in", got.History)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// WriteJSON writes contacts as an indented JSON array, the format of
+// GET /api/contacts.
+func WriteJSON(w io.Writer, contacts models.Contacts) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if contacts == nil {
+		contacts = models.Contacts{}
+	}
+	return enc.Encode(contacts)
+}
+
+// ReadJSON reads contacts from a JSON array written by WriteJSON.
+//
+// Contacts are validated like rows of ReadCSV, and their status history is
+// kept. If any contact is invalid no contacts are returned, and the error
+// lists every invalid contact.
+func ReadJSON(r io.Reader, fields models.FieldDefinitions, phoneRegion string) (models.Contacts, error) {
+	var raw models.Contacts
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error decoding json contacts: %v", err)
+	}
+
+	var (
+		contacts models.Contacts
+		errs     []error
+		ids      = idSet{}
+	)
+
+	for i, c := range raw {
+		get := func(name string) string {
+			switch name {
+			case "id":
+				if c.ID == uuid.Nil {
+					return ""
+				}
+				return c.ID.String()
+			case "name":
+				return strings.TrimSpace(c.Name)
+			case "email":
+				return strings.TrimSpace(c.Email)
+			case "phone":
+				return strings.TrimSpace(c.Phone)
+			case "status":
+				return c.Status.String()
+			case "tags":
+				return strings.Join(c.Tags, ",")
+			}
+			return ""
+		}
+
+		contact, fieldErrs := contactFromRecord(get, c.Custom, fields, phoneRegion)
+		if fieldErrs.Any() {
+			errs = append(errs, fmt.Errorf("contact %d: %v", i+1, fieldErrs))
+			continue
+		}
+		if ids.seen(contact.ID) {
+			errs = append(errs, fmt.Errorf("contact %d: duplicate id %s", i+1, contact.ID))
+			continue
+		}
+		contact.History = c.History
+		contacts = append(contacts, contact)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return contacts, nil
+}
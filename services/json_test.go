@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestReadJSON(t *testing.T) {
+	id := uuid.New().String()
+	fields := models.FieldDefinitions{{Key: "size", Label: "T-shirt size", Type: models.FieldSelect, Options: []string{"S", "M", "L"}}}
+
+	tests := []struct {
+		name    string
+		json    string
+		want    int
+		wantErr string
+	}{
+		{"empty", `[]`, 0, ""},
+		{"valid", `[{"name":"Ada Lovelace","email":"ada@example.com","phone":"+12025550123","custom":{"size":"M"}}]`, 1, ""},
+		{"not an array", `{"name":"Ada"}`, 0, "error decoding json contacts"},
+		{"invalid email", `[{"name":"Ada","email":"ada@example.com"},{"name":"Alan","email":"alan"}]`, 0, "contact 2:"},
+		{"invalid custom field", `[{"name":"Ada","email":"ada@example.com","custom":{"size":"XXL"}}]`, 0, "contact 1:"},
+		{"duplicate id", `[{"id":"` + id + `","name":"Ada","email":"ada@example.com"},{"id":"` + id + `","name":"Ada King","email":"ada@example.com"}]`, 0, "contact 2: duplicate id " + id},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contacts, err := ReadJSON(strings.NewReader(test.json), fields, "US")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got error %v, want %q", err, test.wantErr)
+				}
+				if contacts != nil {
+					t.Errorf("got %d contacts, want none", len(contacts))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(contacts) != test.want {
+				t.Errorf("got %d contacts, want %d", len(contacts), test.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	contacts := FakeContacts(20, 7)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, contacts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadJSON(&buf, nil, "US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(contacts) {
+		t.Fatalf("got %d contacts, want %d", len(got), len(contacts))
+	}
+	for i, c := range got {
+		want := contacts[i]
+		if c.ID != want.ID || c.Name != want.Name || c.Email != want.Email || c.Phone != want.Phone || c.Status != want.Status {
+			t.Errorf("contact %d: got %+v, want %+v", i, c, want)
+		}
+		if len(c.History) != len(want.History) || !c.History[0].At.Equal(want.History[0].At) {
+			t.Errorf("contact %d: got history %v, want %v", i, c.History, want.History)
+		}
+	}
+}
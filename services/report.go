@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/internal/pdf"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// ReportInfo heads and footers the pages of an attendance report.
+type ReportInfo struct {
+	EventName string
+	BuildTag  string // Version of the server that generated the report.
+	Generated time.Time
+}
+
+// reportColumn is a column of the roster table of an attendance report.
+type reportColumn struct {
+	title string
+	x     float64 // Left edge, or right edge if right aligned.
+	width float64
+	right bool
+	value func(n int, c models.Contact) string
+}
+
+// Layout of A4 report pages, in points.
+const (
+	reportMargin    = 40.0
+	reportFontSize  = 9.0
+	reportRowHeight = 16.0
+	reportBodyTop   = 84.0
+)
+
+var reportColumns = []reportColumn{
+	{"#", 62, 22, true, func(n int, _ models.Contact) string { return fmt.Sprint(n) }},
+	{"Name", 68, 128, false, func(_ int, c models.Contact) string { return c.Name }},
+	{"Email", 200, 150, false, func(_ int, c models.Contact) string { return c.Email }},
+	{"Status", 354, 62, false, func(_ int, c models.Contact) string { return c.Status.Label() }},
+	{"Checked in", 420, 66, false, func(_ int, c models.Contact) string { return reportTime(c, models.StatusCheckedIn) }},
+	{"Checked out", 490, 66, false, func(_ int, c models.Contact) string { return reportTime(c, models.StatusCheckedOut) }},
+}
+
+// reportTime returns when c last entered status, or "" if never.
+func reportTime(c models.Contact, status models.Status) string {
+	at, ok := c.LastChangeTo(status)
+	if !ok {
+		return ""
+	}
+	return at.Format("Jan 2 15:04")
+}
+
+// WriteAttendanceReport writes a printable PDF report of contacts: the
+// totals per status, the roster sorted by name with check-in and check-out
+// times, and lines to sign it. Every page is headed by the event name and
+// footed by when and by which build it was generated.
+func WriteAttendanceReport(w io.Writer, contacts models.Contacts, info ReportInfo) error {
+	doc := pdf.New(pdf.A4)
+	doc.Title = strings.TrimSpace(info.EventName + " attendance report")
+	doc.Creator = "headcount " + info.BuildTag
+	doc.Created = info.Generated
+
+	page := doc.AddPage()
+	y := writeReportTotals(page, reportBodyTop, contacts)
+
+	contacts = slices.Clone(contacts)
+	slices.SortStableFunc(contacts, func(a, b models.Contact) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	bottom := doc.Size.Height - reportMargin - 24
+	y += 24
+	y = writeReportTableHeader(page, y)
+	for i, c := range contacts {
+		if y+reportRowHeight > bottom {
+			page = doc.AddPage()
+			y = writeReportTableHeader(page, reportBodyTop)
+		}
+		y += reportRowHeight
+		for _, col := range reportColumns {
+			text := pdf.Truncate(pdf.Regular, reportFontSize, col.value(i+1, c), col.width)
+			if text == "" {
+				continue
+			}
+			if col.right {
+				page.TextRight(col.x, y, pdf.Regular, reportFontSize, text)
+			} else {
+				page.Text(col.x, y, pdf.Regular, reportFontSize, text)
+			}
+		}
+	}
+
+	if y+72 > bottom {
+		page = doc.AddPage()
+		y = reportBodyTop
+	}
+	y += 60
+	for _, x := range []float64{reportMargin, 320} {
+		page.Line(x, y, x+200, y, 0.5)
+	}
+	page.Text(reportMargin, y+12, pdf.Regular, reportFontSize, "Signature")
+	page.Text(320, y+12, pdf.Regular, reportFontSize, "Date")
+
+	for i := 0; i < doc.Pages(); i++ {
+		writeReportHeaderFooter(doc, doc.Page(i), i, info)
+	}
+	return doc.Write(w)
+}
+
+// writeReportTotals writes the number and share of contacts in each status
+// from y, returning the y of the last line.
+func writeReportTotals(page *pdf.Page, y float64, contacts models.Contacts) float64 {
+	counts := map[models.Status]int{}
+	for _, c := range contacts {
+		counts[c.Status.Canonical()]++
+	}
+
+	page.Text(reportMargin, y, pdf.Bold, 12, "Totals")
+	y += 6
+	line := func(font pdf.Font, label string, n int) {
+		y += reportRowHeight
+		page.Text(reportMargin, y, font, reportFontSize+1, label)
+		page.TextRight(220, y, font, reportFontSize+1, fmt.Sprint(n))
+		if len(contacts) > 0 {
+			page.TextRight(280, y, font, reportFontSize+1, fmt.Sprintf("%.1f%%", 100*float64(n)/float64(len(contacts))))
+		}
+	}
+	for _, status := range models.Statuses {
+		line(pdf.Regular, status.Label(), counts[status])
+	}
+	page.Line(reportMargin, y+5, 280, y+5, 0.5)
+	y += 4
+	line(pdf.Bold, "Total", len(contacts))
+	return y
+}
+
+// writeReportTableHeader writes the titles of reportColumns on a shaded
+// row from y, returning the y of their baseline.
+func writeReportTableHeader(page *pdf.Page, y float64) float64 {
+	page.Box(reportMargin, y, pdf.A4.Width-2*reportMargin, reportRowHeight+2, 0.9)
+	y += reportRowHeight - 4
+	for _, col := range reportColumns {
+		if col.right {
+			page.TextRight(col.x, y, pdf.Bold, reportFontSize, col.title)
+		} else {
+			page.Text(col.x, y, pdf.Bold, reportFontSize, col.title)
+		}
+	}
+	return y
+}
+
+func writeReportHeaderFooter(doc *pdf.Document, page *pdf.Page, i int, info ReportInfo) {
+	right := doc.Size.Width - reportMargin
+
+	title := "Attendance report"
+	if info.EventName != "" {
+		title = info.EventName
+	}
+	page.Text(reportMargin, 52, pdf.Bold, 14, pdf.Truncate(pdf.Bold, 14, title, right-reportMargin-110))
+	if info.EventName != "" {
+		page.TextRight(right, 52, pdf.Regular, reportFontSize+1, "Attendance report")
+	}
+	page.Line(reportMargin, 60, right, 60, 0.75)
+
+	y := doc.Size.Height - reportMargin
+	page.Line(reportMargin, y-12, right, y-12, 0.5)
+	page.Text(reportMargin, y, pdf.Regular, reportFontSize-1,
+		fmt.Sprintf("Generated %s by headcount %s", info.Generated.Format("2006-01-02 15:04 MST"), info.BuildTag))
+	page.TextRight(right, y, pdf.Regular, reportFontSize-1, fmt.Sprintf("Page %d of %d", i+1, doc.Pages()))
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// seedEpoch is when the made-up event of FakeContacts opens its doors.
+var seedEpoch = time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+var (
+	seedFirstNames = []string{
+		"Ada", "Alan", "Amara", "Ben", "Chen", "Dara", "Elena", "Farah", "Gabriel", "Hana",
+		"Ivan", "Jamal", "Kiran", "Lena", "Mateo", "Nadia", "Omar", "Priya", "Quinn", "Rosa",
+		"Sven", "Tariq", "Uma", "Victor", "Wen", "Ximena", "Yusuf", "Zoe",
+	}
+	seedLastNames = []string{
+		"Adeyemi", "Bauer", "Costa", "Dubois", "Eriksen", "Fernandes", "Garcia", "Haddad", "Ito", "Jensen",
+		"Kowalski", "Lopez", "Mensah", "Nakamura", "Okafor", "Petrov", "Quispe", "Rossi", "Singh", "Tanaka",
+		"Usman", "Varga", "Wong", "Yilmaz", "Zhang",
+	}
+	seedTags = []string{"vip", "speaker", "staff", "volunteer", "sponsor", "press"}
+
+	// seedAreaCodes are combined with the fictional 555-0100 to 555-0199
+	// lines, so made-up numbers never reach anyone.
+	seedAreaCodes = []string{"202", "212", "312", "415", "512", "617", "702", "808", "906", "971"}
+)
+
+// FakeContacts returns n made-up contacts of an event opening at seedEpoch.
+// The same seed always yields the same contacts, ids and history included,
+// for reproducible demos. Importing the same seed again thus updates those
+// contacts in place rather than adding more; use another seed to add more.
+//
+// Names and emails are unique, and phone numbers are for up to 1000
+// contacts, so that seeded rosters show no duplicates.
+func FakeContacts(n int, seed uint64) models.Contacts {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	contacts := make(models.Contacts, 0, n)
+
+	for i := 0; i < n; i++ {
+		first := seedFirstNames[rng.IntN(len(seedFirstNames))]
+		last := seedLastNames[rng.IntN(len(seedLastNames))]
+
+		id, err := uuid.NewRandomFromReader(rngReader{rng})
+		if err != nil {
+			panic(err) // rngReader never fails.
+		}
+
+		c := models.Contact{
+			ID:    id,
+			Name:  first + " " + last,
+			Email: fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+			Phone: fmt.Sprintf("+1%s5550%03d", seedAreaCodes[(i/100)%len(seedAreaCodes)], 100+i%100),
+		}
+
+		for _, tag := range seedTags {
+			if rng.IntN(5) == 0 {
+				c.Tags = append(c.Tags, tag)
+			}
+		}
+
+		registered := seedEpoch.Add(-time.Duration(rng.IntN(30*24)) * time.Hour)
+		recordStatus(&c, models.StatusRegistered, registered)
+
+		checkIn := seedEpoch.Add(time.Duration(rng.IntN(180)) * time.Minute)
+		switch p := rng.IntN(10); {
+		case p < 4: // Still registered.
+		case p < 7:
+			recordStatus(&c, models.StatusCheckedIn, checkIn)
+		case p < 8:
+			recordStatus(&c, models.StatusCheckedIn, checkIn)
+			recordStatus(&c, models.StatusCheckedOut, checkIn.Add(time.Duration(60+rng.IntN(240))*time.Minute))
+		case p < 9:
+			recordStatus(&c, models.StatusNoShow, seedEpoch.Add(8*time.Hour))
+		default:
+			recordStatus(&c, models.StatusCancelled, registered.Add(time.Duration(rng.IntN(72))*time.Hour))
+		}
+
+		contacts = append(contacts, c)
+	}
+
+	return contacts
+}
+
+// rngReader reads random bytes from a seeded generator, for deterministic
+// UUIDs.
+type rngReader struct{ rng *rand.Rand }
+
+func (r rngReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r.rng.Uint32())
+	}
+	return len(p), nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lloydlobo/go-headcount/internal"
+)
+
+func TestFakeContacts(t *testing.T) {
+	a, b := FakeContacts(300, 42), FakeContacts(300, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("got different contacts for the same seed")
+	}
+	if other := FakeContacts(300, 43); reflect.DeepEqual(a, other) {
+		t.Error("got the same contacts for different seeds")
+	}
+	if got := len(FakeContacts(0, 42)); got != 0 {
+		t.Errorf("got %d contacts, want 0", got)
+	}
+
+	seen := map[string]bool{}
+	for _, c := range a {
+		for _, key := range []string{"id:" + c.ID.String(), "email:" + c.Email, "phone:" + c.Phone} {
+			if seen[key] {
+				t.Errorf("duplicate %s", key)
+			}
+			seen[key] = true
+		}
+		if len(c.History) == 0 || c.History[len(c.History)-1].Status != c.Status {
+			t.Errorf("contact %s: history %v does not end in status %s", c.Name, c.History, c.Status)
+		}
+	}
+}
+
+func TestFakeContactsReseed(t *testing.T) {
+	cs := NewContactService(internal.DefaultConfig())
+	tests := []struct {
+		seed                   uint64
+		wantAdded, wantUpdated int
+	}{
+		{1, 10, 0},
+		{1, 0, 10},
+		{2, 10, 0},
+	}
+	for _, tt := range tests {
+		added, updated := cs.Import(FakeContacts(10, tt.seed))
+		if added != tt.wantAdded || updated != tt.wantUpdated {
+			t.Errorf("seed %d: got %d added, %d updated, want %d, %d", tt.seed, added, updated, tt.wantAdded, tt.wantUpdated)
+		}
+	}
+	if got := cs.Count(); got != 20 {
+		t.Errorf("got %d contacts, want 20", got)
+	}
+}
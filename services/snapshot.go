@@ -0,0 +1,297 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotFormat identifies snapshot files, and SnapshotVersion is the
+// version of their layout written by WriteSnapshot.
+//
+// Bump SnapshotVersion when the layout changes, appending a migration from
+// the previous version to snapshotMigrations, so that older snapshots can
+// still be restored.
+const (
+	SnapshotFormat  = "headcount-snapshot"
+	SnapshotVersion = 1
+)
+
+var (
+	ErrSnapshotFormat  error = errors.New("not a headcount snapshot")
+	ErrSnapshotVersion error = errors.New("snapshot from a newer version of headcount")
+)
+
+// snapshotFile is the layout of a snapshot at SnapshotVersion: a versioned
+// storeFile.
+type snapshotFile struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	storeFile
+}
+
+// snapshotMigrations upgrade the fields of a snapshot of version i to
+// version i+1. Version 0 is a data file written by ContactService.Save,
+// which has the layout of version 1 without its header.
+var snapshotMigrations = []func(fields map[string]json.RawMessage) error{
+	0: func(map[string]json.RawMessage) error { return nil },
+}
+
+// WriteSnapshot writes the roster and custom fields as a snapshot.
+func (cs *ContactService) WriteSnapshot(w io.Writer) error {
+	cs.lock.Lock()
+	b, err := json.MarshalIndent(snapshotFile{
+		Format:    SnapshotFormat,
+		Version:   SnapshotVersion,
+		Created:   time.Now(),
+		storeFile: storeFile{Contacts: cs.Contacts, Fields: cs.fields},
+	}, "", "  ")
+	cs.lock.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %w", err)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// RestoreSnapshot replaces the roster and custom fields with the ones of a
+// snapshot written by WriteSnapshot, of this or an older version, or of a
+// data file written by Save. The snapshot is checked in full first, so an
+// invalid one leaves the roster untouched.
+func (cs *ContactService) RestoreSnapshot(r io.Reader) error {
+	store, err := readSnapshot(r)
+	if err != nil {
+		return err
+	}
+	cs.replace(store)
+	return nil
+}
+
+func readSnapshot(r io.Reader) (storeFile, error) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&fields); err != nil {
+		return storeFile{}, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+
+	var header struct {
+		Format  string `json:"format"`
+		Version int    `json:"version"`
+	}
+	for key, dst := range map[string]any{"format": &header.Format, "version": &header.Version} {
+		if raw, ok := fields[key]; ok {
+			if err := json.Unmarshal(raw, dst); err != nil {
+				return storeFile{}, fmt.Errorf("error decoding snapshot %s: %w", key, err)
+			}
+		}
+	}
+	switch {
+	case header.Format == "" && fields["contacts"] == nil: // Neither a snapshot nor a data file.
+		return storeFile{}, ErrSnapshotFormat
+	case header.Format != "" && header.Format != SnapshotFormat, header.Version < 0:
+		return storeFile{}, ErrSnapshotFormat
+	case header.Version > SnapshotVersion:
+		return storeFile{}, fmt.Errorf("%w: version %d, want at most %d", ErrSnapshotVersion, header.Version, SnapshotVersion)
+	}
+
+	for v := header.Version; v < SnapshotVersion; v++ {
+		if err := snapshotMigrations[v](fields); err != nil {
+			return storeFile{}, fmt.Errorf("error migrating snapshot from version %d: %w", v, err)
+		}
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return storeFile{}, err
+	}
+	var snapshot snapshotFile
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return storeFile{}, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(snapshot.Contacts))
+	for i, c := range snapshot.Contacts {
+		if c.ID == uuid.Nil || seen[c.ID] {
+			return storeFile{}, fmt.Errorf("error decoding snapshot: contact %d: missing or duplicate id", i+1)
+		}
+		seen[c.ID] = true
+	}
+	for _, fd := range snapshot.Fields {
+		if err := fd.Check(); err != nil {
+			return storeFile{}, fmt.Errorf("error decoding snapshot: %w", err)
+		}
+	}
+	return snapshot.storeFile, nil
+}
+
+// Snapshots saves snapshots of a ContactService to a directory, keeping the
+// newest ones.
+type Snapshots struct {
+	Dir  string
+	Keep int // Number of snapshots kept, at least 1.
+
+	cs   *ContactService
+	mu   sync.Mutex // Serializes saves.
+	last []byte     // Roster of the last snapshot saved, to skip unchanged ones.
+}
+
+// snapshotPrefix and snapshotExt name snapshot files, with the UTC time of
+// the snapshot in between so that names sort by age.
+const (
+	snapshotPrefix = "snapshot-"
+	snapshotExt    = ".json"
+	snapshotLayout = "20060102T150405.000Z"
+)
+
+// NewSnapshots returns the snapshots of cs in dir, creating dir if missing.
+func NewSnapshots(cs *ContactService, dir string, keep int) (*Snapshots, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating snapshot directory: %w", err)
+	}
+	return &Snapshots{Dir: dir, Keep: max(keep, 1), cs: cs}, nil
+}
+
+// Save writes a snapshot of the roster atomically, then removes the oldest
+// snapshots beyond Keep. It returns the path of the snapshot.
+func (s *Snapshots) Save() (string, error) {
+	return s.save(false)
+}
+
+// SaveIfChanged saves a snapshot unless the roster is the same as in the
+// last one saved. It returns the path of the snapshot, or "" if skipped.
+func (s *Snapshots) SaveIfChanged() (string, error) {
+	return s.save(true)
+}
+
+func (s *Snapshots) save(ifChanged bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := s.cs.WriteSnapshot(&buf); err != nil {
+		return "", err
+	}
+	roster := rosterOf(buf.Bytes())
+	if ifChanged && s.last != nil && bytes.Equal(roster, s.last) {
+		return "", nil
+	}
+
+	path := filepath.Join(s.Dir, snapshotPrefix+time.Now().UTC().Format(snapshotLayout)+snapshotExt)
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return "", err
+	}
+	s.last = roster
+
+	return path, s.prune()
+}
+
+// rosterOf returns snapshot without its creation time.
+func rosterOf(snapshot []byte) []byte {
+	var store storeFile
+	if json.Unmarshal(snapshot, &store) != nil {
+		return nil
+	}
+	b, _ := json.Marshal(store)
+	return b
+}
+
+func (s *Snapshots) prune() error {
+	names, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range names[min(s.Keep, len(names)):] {
+		if err := os.Remove(filepath.Join(s.Dir, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// List returns the file names of the snapshots in Dir, newest first.
+func (s *Snapshots) List() ([]string, error) { return listSnapshots(s.Dir) }
+
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing snapshots: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && IsSnapshotName(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names, nil
+}
+
+// IsSnapshotName reports whether name is the file name of a snapshot saved
+// by Snapshots, e.g. to serve it without exposing other files.
+func IsSnapshotName(name string) bool {
+	_, ok := snapshotTime(name)
+	return ok
+}
+
+// snapshotTime returns the time in the file name of a snapshot.
+func snapshotTime(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, snapshotPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, ok = strings.CutSuffix(stamp, snapshotExt)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(snapshotLayout, stamp)
+	return at, err == nil
+}
+
+// LatestSnapshot returns the path and time of the newest snapshot in dir,
+// or an error wrapping fs.ErrNotExist if there is none.
+func LatestSnapshot(dir string) (path string, at time.Time, err error) {
+	names, err := listSnapshots(dir)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if len(names) == 0 {
+		return "", time.Time{}, fmt.Errorf("no snapshots in %s: %w", dir, fs.ErrNotExist)
+	}
+
+	at, _ = snapshotTime(names[0])
+	return filepath.Join(dir, names[0]), at, nil
+}
+
+// Run saves a snapshot every interval, if the roster changed, until ctx is
+// done. Errors are passed to onError.
+func (s *Snapshots) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.SaveIfChanged(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	saved := NewContactService(internal.DefaultConfig())
+	saved.Import(FakeContacts(10, 3))
+	if err := saved.DefineField(models.FieldDefinition{Key: "company", Label: "Company", Type: models.FieldText}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := saved.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewContactService(internal.DefaultConfig())
+	restored.Import(FakeContacts(2, 4))
+	if err := restored.RestoreSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Count() != 10 {
+		t.Fatalf("got %d contacts, want 10", restored.Count())
+	}
+	for i, c := range restored.Contacts {
+		if want := saved.Contacts[i]; c.ID != want.ID || c.Name != want.Name || len(c.History) != len(want.History) {
+			t.Errorf("contact %d: got %+v, want %+v", i, c, want)
+		}
+	}
+	if got := restored.Fields(); len(got) != 1 || got[0].Key != "company" {
+		t.Errorf("got fields %v, want company", got)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	contact := `{"id":"0b6c8cf4-3d5e-4a43-9d7b-1c8f3f0e6a11","name":"Ada","email":"ada@example.com","status":"Registered"}`
+
+	tests := []struct {
+		name      string
+		snapshot  string
+		wantCount int
+		wantErr   error  // Matched with errors.Is, if set.
+		wantText  string // Contained in the error, if set.
+	}{
+		{"version 1", `{"format":"headcount-snapshot","version":1,"contacts":[` + contact + `]}`, 1, nil, ""},
+		{"data file as version 0", `{"contacts":[` + contact + `],"fields":[{"key":"company","label":"Company","type":"text"}]}`, 1, nil, ""},
+		{"empty roster", `{"format":"headcount-snapshot","version":1,"contacts":[]}`, 0, nil, ""},
+		{"negative version", `{"format":"headcount-snapshot","version":-1,"contacts":[]}`, 0, ErrSnapshotFormat, ""},
+		{"newer version", `{"format":"headcount-snapshot","version":2,"contacts":[]}`, 0, ErrSnapshotVersion, ""},
+		{"other format", `{"format":"something-else","version":1,"contacts":[]}`, 0, ErrSnapshotFormat, ""},
+		{"not a snapshot", `{"name":"Ada"}`, 0, ErrSnapshotFormat, ""},
+		{"not json", `contacts`, 0, nil, "error decoding snapshot"},
+		{"bad version", `{"format":"headcount-snapshot","version":"1"}`, 0, nil, "error decoding snapshot version"},
+		{"duplicate ids", `{"contacts":[` + contact + `,` + contact + `]}`, 0, nil, "contact 2: missing or duplicate id"},
+		{"missing id", `{"contacts":[{"name":"Ada"}]}`, 0, nil, "contact 1: missing or duplicate id"},
+		{"invalid field", `{"contacts":[],"fields":[{"key":"Bad Key","label":"Bad","type":"text"}]}`, 0, nil, "invalid field key"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs := NewContactService(internal.DefaultConfig())
+			cs.Import(FakeContacts(3, 1))
+
+			err := cs.RestoreSnapshot(strings.NewReader(test.snapshot))
+			if test.wantErr == nil && test.wantText == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cs.Count() != test.wantCount {
+					t.Errorf("got %d contacts, want %d", cs.Count(), test.wantCount)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v, want %v", err, test.wantErr)
+			}
+			if test.wantText != "" && !strings.Contains(err.Error(), test.wantText) {
+				t.Errorf("got error %v, want %q", err, test.wantText)
+			}
+			if cs.Count() != 3 {
+				t.Errorf("got %d contacts after a failed restore, want the 3 kept", cs.Count())
+			}
+		})
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	cs := NewContactService(internal.DefaultConfig())
+	cs.Import(FakeContacts(3, 1))
+
+	s, err := NewSnapshots(cs, dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond) // Snapshot names have millisecond precision.
+		path, err := s.Save()
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	// Only the newest Keep snapshots are kept, and other files are left alone.
+	names, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != filepath.Base(paths[2]) || names[1] != filepath.Base(paths[1]) {
+		t.Errorf("got snapshots %v, want the newest 2 of %v", names, paths)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("other file removed: %v", err)
+	}
+
+	latest, _, err := LatestSnapshot(dir)
+	if err != nil || latest != paths[2] {
+		t.Errorf("got latest %q, %v, want %q", latest, err, paths[2])
+	}
+
+	// Unchanged rosters are skipped.
+	time.Sleep(2 * time.Millisecond)
+	if path, err := s.SaveIfChanged(); err != nil || path != "" {
+		t.Errorf("unchanged: got %q, %v, want it skipped", path, err)
+	}
+	cs.Import(FakeContacts(1, 2))
+	if path, err := s.SaveIfChanged(); err != nil || path == "" {
+		t.Errorf("changed: got %q, %v, want a snapshot", path, err)
+	}
+
+	// Snapshots restore the roster they were saved from.
+	latest, _, _ = LatestSnapshot(dir)
+	f, err := os.Open(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	restored := NewContactService(internal.DefaultConfig())
+	if err := restored.RestoreSnapshot(f); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Count() != 4 {
+		t.Errorf("got %d contacts, want 4", restored.Count())
+	}
+}
+
+func TestIsSnapshotName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"snapshot-20240301T090000.000Z.json", true},
+		{"snapshot-20240301T090000.000Z.json.tmp", false},
+		{".snapshot-20240301T090000.000Z.json.123.tmp", false},
+		{"snapshot-latest.json", false},
+		{"../snapshot-20240301T090000.000Z.json", false},
+		{"contacts.json", false},
+	}
+
+	for _, test := range tests {
+		if got := IsSnapshotName(test.name); got != test.want {
+			t.Errorf("IsSnapshotName(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLatestSnapshotEmpty(t *testing.T) {
+	if _, _, err := LatestSnapshot(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want os.ErrNotExist", err)
+	}
+}
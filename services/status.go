@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+var (
+	ErrInvalidTransition error = errors.New("invalid status transition")
+)
+
+// statusTransitions is the attendance state machine: the statuses each status
+// may change to. Staying in the same status is always allowed.
+var statusTransitions = map[models.Status][]models.Status{
+	models.StatusRegistered: {models.StatusCheckedIn, models.StatusNoShow, models.StatusCancelled},
+	models.StatusCheckedIn:  {models.StatusCheckedOut},
+	models.StatusCheckedOut: {models.StatusCheckedIn},
+	models.StatusNoShow:     {models.StatusCheckedIn, models.StatusRegistered}, // Late arrival, or undo.
+	models.StatusCancelled:  {models.StatusRegistered},                         // Re-registration.
+}
+
+// CanTransition reports whether a contact may change from status from to
+// status to. Legacy statuses are compared by their Canonical status.
+func CanTransition(from, to models.Status) bool {
+	from, to = from.Canonical(), to.Canonical()
+	if from == to {
+		return true
+	}
+
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextStatuses returns the statuses a contact in status from may change to,
+// from itself included, in lifecycle order.
+func NextStatuses(from models.Status) []models.Status {
+	var next []models.Status
+	for _, to := range models.Statuses {
+		if CanTransition(from, to) {
+			next = append(next, to)
+		}
+	}
+	return next
+}
+
+// CheckTransition returns ErrInvalidTransition if from may not change to to.
+func CheckTransition(from, to models.Status) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: cannot change from %s to %s", ErrInvalidTransition, from.Label(), to.Label())
+	}
+	return nil
+}
+
+// recordStatus sets the canonical status of c and appends it to the
+// attendance history unless c is already in that status.
+func recordStatus(c *models.Contact, status models.Status, at time.Time) {
+	status = status.Canonical()
+	if status == "" {
+		status = models.StatusRegistered
+	}
+
+	if len(c.History) > 0 && c.History[len(c.History)-1].Status == status {
+		c.Status = status
+		return
+	}
+
+	c.Status = status
+	c.History = append(c.History, models.StatusChange{Status: status, At: at})
+}
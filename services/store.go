@@ -0,0 +1,87 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// storeFile is the on-disk form of the roster, see ContactService.Save.
+type storeFile struct {
+	Contacts models.Contacts         `json:"contacts"`
+	Fields   models.FieldDefinitions `json:"fields,omitempty"`
+}
+
+// Load replaces the roster and custom fields with the ones saved at path.
+// A missing file is reported with an error wrapping fs.ErrNotExist.
+func (cs *ContactService) Load(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading data file: %w", err)
+	}
+
+	var store storeFile
+	if err := json.Unmarshal(b, &store); err != nil {
+		return fmt.Errorf("error decoding data file %s: %w", path, err)
+	}
+
+	cs.replace(store)
+	return nil
+}
+
+// replace replaces the roster and custom fields with the ones of store.
+func (cs *ContactService) replace(store storeFile) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	cs.Contacts = store.Contacts
+	if cs.Contacts == nil {
+		cs.Contacts = models.Contacts{}
+	}
+	cs.fields = store.Fields
+	cs.idCounter = len(store.Contacts)
+	cs.seq = len(store.Contacts) + 1
+}
+
+// Save writes the roster and custom fields to path. The file is replaced
+// atomically, so a crash mid-write leaves the previous save intact.
+func (cs *ContactService) Save(path string) error {
+	cs.lock.Lock()
+	b, err := json.MarshalIndent(storeFile{Contacts: cs.Contacts, Fields: cs.fields}, "", "  ")
+	cs.lock.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding data file: %w", err)
+	}
+
+	return writeFileAtomic(path, b)
+}
+
+// writeFileAtomic writes b to a temporary file next to path, then renames
+// it over path.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed.
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error syncing %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+	return nil
+}
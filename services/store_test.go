@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.json")
+
+	saved := NewContactService(internal.DefaultConfig())
+	saved.Import(FakeContacts(5, 1))
+	if err := saved.DefineField(models.FieldDefinition{Key: "company", Label: "Company", Type: models.FieldText}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewContactService(internal.DefaultConfig())
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Count() != 5 {
+		t.Fatalf("got %d contacts, want 5", loaded.Count())
+	}
+	for i, c := range loaded.Contacts {
+		want := saved.Contacts[i]
+		if c.ID != want.ID || c.Name != want.Name || c.Status != want.Status || len(c.History) != len(want.History) {
+			t.Errorf("contact %d: got %+v, want %+v", i, c, want)
+		}
+	}
+	if got := loaded.Fields(); len(got) != 1 || got[0].Key != "company" {
+		t.Errorf("got fields %v, want company", got)
+	}
+
+	// Saving replaces the file without leaving temporary files behind.
+	if err := loaded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := dirNames(entries); !slices.Equal(names, []string{"contacts.json"}) {
+		t.Errorf("got files %v, want only contacts.json", names)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	cs := NewContactService(internal.DefaultConfig())
+
+	if err := cs.Load(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, want fs.ErrNotExist", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte(`{"contacts": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cs.Import(FakeContacts(2, 1))
+	if err := cs.Load(corrupt); err == nil {
+		t.Error("corrupt file: got no error")
+	}
+	if cs.Count() != 2 {
+		t.Errorf("got %d contacts after a failed load, want the 2 kept", cs.Count())
+	}
+}
+
+func dirNames(entries []os.DirEntry) []string {
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// Filter returns the contacts selected by f, in roster order.
+func (cs *ContactService) Filter(f models.ContactFilter) models.Contacts {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	contacts := models.Contacts{}
+	for _, c := range cs.Contacts {
+		if f.Match(c) {
+			contacts = append(contacts, c)
+		}
+	}
+
+	return contacts
+}
+
+// CountFiltered returns the number of contacts selected by f.
+func (cs *ContactService) CountFiltered(f models.ContactFilter) (count int) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	for _, c := range cs.Contacts {
+		if f.Match(c) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Tags returns every tag in use with its active/inactive counts, sorted by
+// tag. Tags differing only in case are counted together under the first
+// spelling seen.
+func (cs *ContactService) Tags() []models.TagCount {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	byKey := make(map[string]*models.TagCount)
+	for _, c := range cs.Contacts {
+		for _, tag := range c.Tags {
+			key := strings.ToLower(tag)
+			tc, ok := byKey[key]
+			if !ok {
+				tc = &models.TagCount{Tag: tag}
+				byKey[key] = tc
+			}
+
+			tc.Total++
+			if c.Status.IsEnabled() {
+				tc.Active++
+			} else {
+				tc.Inactive++
+			}
+		}
+	}
+
+	counts := make([]models.TagCount, 0, len(byKey))
+	for _, tc := range byKey {
+		counts = append(counts, *tc)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return strings.ToLower(counts[i].Tag) < strings.ToLower(counts[j].Tag)
+	})
+
+	return counts
+}
+
+// TagContacts adds tag to the contacts with ids and returns how many changed.
+func (cs *ContactService) TagContacts(ids []uuid.UUID, tag string) (changed int) {
+	return cs.updateTags(ids, func(tags []string) []string { return models.AddTag(tags, tag) })
+}
+
+// UntagContacts removes tag from the contacts with ids and returns how many changed.
+func (cs *ContactService) UntagContacts(ids []uuid.UUID, tag string) (changed int) {
+	return cs.updateTags(ids, func(tags []string) []string { return models.RemoveTag(tags, tag) })
+}
+
+// RenameTag renames tag on every contact, merging it into newTag when a
+// contact already has both, and returns how many contacts changed.
+func (cs *ContactService) RenameTag(tag, newTag string) (changed int) {
+	return cs.updateTags(nil, func(tags []string) []string {
+		if !containsFold(tags, tag) {
+			return tags
+		}
+		return models.AddTag(models.RemoveTag(tags, tag), newTag)
+	})
+}
+
+// DeleteTag removes tag from every contact and returns how many changed.
+func (cs *ContactService) DeleteTag(tag string) (changed int) {
+	return cs.updateTags(nil, func(tags []string) []string { return models.RemoveTag(tags, tag) })
+}
+
+// updateTags applies fn to the tags of the contacts with ids, or of every
+// contact if ids is nil, under a single lock.
+func (cs *ContactService) updateTags(ids []uuid.UUID, fn func([]string) []string) (changed int) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	selected := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	for i, c := range cs.Contacts {
+		if ids != nil && !selected[c.ID] {
+			continue
+		}
+
+		tags := fn(append([]string(nil), c.Tags...))
+		if strings.Join(tags, "\x00") != strings.Join(c.Tags, "\x00") {
+			cs.Contacts[i].Tags = tags
+			changed++
+		}
+	}
+
+	return changed
+}
+
+func containsFold(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, models.NormalizeTag(tag)) {
+			return true
+		}
+	}
+	return false
+}
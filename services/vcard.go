@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// vCardLineLength is the octet limit of a content line before folding,
+// see RFC 2425 section 5.8.1.
+const vCardLineLength = 75
+
+// WriteVCard writes contacts as vCard 3.0 cards, one per contact, e.g. to be
+// imported into a phone's address book.
+func WriteVCard(w io.Writer, contacts models.Contacts) error {
+	bw := bufio.NewWriter(w)
+
+	for _, c := range contacts {
+		family, given := splitName(c.Name)
+
+		lines := []string{
+			"BEGIN:VCARD",
+			"VERSION:3.0",
+			"UID:urn:uuid:" + c.ID.String(),
+			"FN:" + escapeVCard(c.Name),
+			"N:" + escapeVCard(family) + ";" + escapeVCard(given) + ";;;",
+		}
+		if c.Email != "" {
+			lines = append(lines, "EMAIL;TYPE=INTERNET:"+escapeVCard(c.Email))
+		}
+		if c.Phone != "" {
+			lines = append(lines, "TEL;TYPE=CELL:"+escapeVCard(c.Phone))
+		}
+		if len(c.Tags) > 0 {
+			tags := make([]string, len(c.Tags))
+			for i, tag := range c.Tags {
+				tags[i] = escapeVCard(tag)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		lines = append(lines, "END:VCARD")
+
+		for _, line := range lines {
+			if _, err := bw.WriteString(foldVCardLine(line) + "\r\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// splitName splits a full name into family and given names at its last
+// space, the best guess for names written given name first.
+func splitName(name string) (family, given string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexByte(name, ' '); i > 0 {
+		return name[i+1:], strings.TrimSpace(name[:i])
+	}
+	return name, ""
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeVCard escapes a text value.
+func escapeVCard(s string) string { return vCardEscaper.Replace(s) }
+
+// foldVCardLine folds line into lines of at most vCardLineLength octets,
+// continued by a leading space, without splitting UTF-8 sequences.
+func foldVCardLine(line string) string {
+	if len(line) <= vCardLineLength {
+		return line
+	}
+
+	var b strings.Builder
+	limit := vCardLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = vCardLineLength - 1 // Continuations start with a space.
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// ReadVCard reads contacts from vCard 3.0 and 4.0 cards, e.g. a file of
+// several cards shared from a phone.
+//
+// FN, or N if missing, gives the name, the preferred EMAIL and TEL the email
+// and phone, CATEGORIES the tags and a UUID in UID the id. Other properties
+// are ignored. Contacts are validated like rows of ReadCSV, except that
+// custom fields are never required, as cards do not carry them. If any card
+// is invalid no contacts are returned, and the error lists every invalid
+// card.
+func ReadVCard(r io.Reader, fields models.FieldDefinitions, phoneRegion string) (models.Contacts, error) {
+	cards, err := readVCards(r)
+	if err != nil {
+		return nil, err
+	}
+
+	optional := make(models.FieldDefinitions, len(fields))
+	for i, fd := range fields {
+		fd.Required = false
+		optional[i] = fd
+	}
+
+	var (
+		contacts models.Contacts
+		errs     []error
+		ids      = idSet{}
+	)
+
+	for i, card := range cards {
+		if version := card.value("VERSION"); version != "3.0" && version != "4.0" {
+			errs = append(errs, fmt.Errorf("card %d: unsupported vCard version %q, want 3.0 or 4.0", i+1, version))
+			continue
+		}
+
+		values := map[string]string{
+			"name":  unescapeVCard(card.value("FN")),
+			"email": unescapeVCard(card.preferred("EMAIL")),
+			"phone": vCardPhone(card.preferred("TEL")),
+		}
+		if values["name"] == "" {
+			values["name"] = vCardStructuredName(card.value("N"))
+		}
+		var tags []string
+		for _, p := range card {
+			if p.name == "CATEGORIES" {
+				tags = append(tags, splitVCardValue(p.value, ',')...)
+			}
+		}
+		values["tags"] = strings.Join(tags, ",")
+		if id, err := uuid.Parse(strings.TrimPrefix(card.value("UID"), "urn:uuid:")); err == nil {
+			values["id"] = id.String()
+		}
+
+		get := func(name string) string { return strings.TrimSpace(values[name]) }
+		contact, fieldErrs := contactFromRecord(get, nil, optional, phoneRegion)
+		if fieldErrs.Any() {
+			errs = append(errs, fmt.Errorf("card %d: %v", i+1, fieldErrs))
+			continue
+		}
+		if ids.seen(contact.ID) {
+			errs = append(errs, fmt.Errorf("card %d: duplicate uid %s", i+1, contact.ID))
+			continue
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return contacts, nil
+}
+
+// vCardProperty is a content line of a card, e.g.
+// "item1.TEL;TYPE=cell,pref:+1 555 0100".
+type vCardProperty struct {
+	name   string              // Upper case, without its group.
+	params map[string][]string // By upper case name.
+	value  string              // Still escaped.
+}
+
+// vCard is the properties of a card, in order.
+type vCard []vCardProperty
+
+// value returns the value of the first property named name.
+func (c vCard) value(name string) string {
+	for _, p := range c {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// preferred returns the value of the property named name marked preferred,
+// by PREF=1 in vCard 4.0 or TYPE=pref in 3.0, else of the first one.
+func (c vCard) preferred(name string) string {
+	best, bestPref := "", 101 // PREF ranges from 1, most preferred, to 100.
+	for _, p := range c {
+		if p.name != name {
+			continue
+		}
+		pref := 100
+		if v := p.params["PREF"]; len(v) > 0 {
+			if n, err := strconv.Atoi(v[0]); err == nil {
+				pref = n
+			}
+		}
+		for _, t := range p.params["TYPE"] {
+			if strings.EqualFold(t, "pref") {
+				pref = 1
+			}
+		}
+		if pref < bestPref {
+			best, bestPref = p.value, pref
+		}
+	}
+	return best
+}
+
+// readVCards reads every card between BEGIN:VCARD and END:VCARD, after
+// unfolding their lines.
+func readVCards(r io.Reader) ([]vCard, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\uFEFF") // Byte order mark.
+		}
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:] // Folded, see foldVCardLine.
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("error reading vcards: %v", err)
+	}
+
+	var (
+		cards []vCard
+		card  vCard
+		depth int // Cards may nest, e.g. in an AGENT property.
+	)
+	for i, line := range lines {
+		p, ok := parseVCardLine(line)
+		if !ok {
+			return nil, fmt.Errorf("error reading vcards: line %d: not a content line: %q", i+1, line)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCARD"):
+			depth++
+			if depth == 1 {
+				card = nil
+			}
+		case p.name == "END" && strings.EqualFold(p.value, "VCARD"):
+			if depth == 1 {
+				cards = append(cards, card)
+			}
+			depth = max(depth-1, 0)
+		case depth == 1:
+			card = append(card, p)
+		}
+	}
+	if depth > 0 {
+		return nil, errors.New("error reading vcards: missing END:VCARD")
+	}
+	if len(cards) == 0 {
+		return nil, errors.New("error reading vcards: no BEGIN:VCARD found")
+	}
+	return cards, nil
+}
+
+// parseVCardLine parses a content line: [group.]name[;param=value...]:value,
+// where parameter values may be quoted.
+func parseVCardLine(line string) (vCardProperty, bool) {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+		colon  = -1
+	)
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		case ':':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				colon = i
+			}
+		}
+	}
+	if colon < 0 || parts[0] == "" {
+		return vCardProperty{}, false
+	}
+
+	name := parts[0]
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	p := vCardProperty{name: strings.ToUpper(name), params: map[string][]string{}, value: line[colon+1:]}
+
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok { // vCard 2.1 style, e.g. ";CELL".
+			key, value = "TYPE", param
+		}
+		key = strings.ToUpper(key)
+		for _, v := range strings.Split(value, ",") {
+			p.params[key] = append(p.params[key], strings.Trim(v, `"`))
+		}
+	}
+	return p, true
+}
+
+// unescapeVCard unescapes a text value, see escapeVCard.
+func unescapeVCard(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default: // `\,`, `\;`, `\\` and unknown escapes.
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitVCardValue splits a compound value at the separators sep that are not
+// escaped, then unescapes each component.
+func splitVCardValue(s string, sep byte) []string {
+	var (
+		parts []string
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, unescapeVCard(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeVCard(s[start:]))
+}
+
+// vCardStructuredName joins the components of an N value, family;given;
+// additional;prefixes;suffixes, in the order names are written.
+func vCardStructuredName(n string) string {
+	c := splitVCardValue(n, ';')
+	for len(c) < 5 {
+		c = append(c, "")
+	}
+	var words []string
+	for _, w := range []string{c[3], c[1], c[2], c[0], c[4]} {
+		if w = strings.TrimSpace(strings.ReplaceAll(w, ",", " ")); w != "" {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// vCardPhone returns the number of a TEL value, either text or a tel: URI as
+// in vCard 4.0, e.g. "tel:+1-555-555-0100;ext=12".
+func vCardPhone(value string) string {
+	if number, ok := strings.CutPrefix(value, "tel:"); ok {
+		number, _, _ = strings.Cut(number, ";")
+		return number
+	}
+	return unescapeVCard(value)
+}
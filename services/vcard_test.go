@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// vcf joins lines into a vCard file with CRLF line endings.
+func vcf(lines ...string) string { return strings.Join(lines, "\r\n") + "\r\n" }
+
+func TestReadVCard(t *testing.T) {
+	id := uuid.New()
+
+	type contact struct {
+		name, email, phone string
+		tags               []string
+	}
+	tests := []struct {
+		name    string
+		vcf     string
+		want    []contact
+		wantErr string
+	}{
+		{
+			name: "multiple cards",
+			vcf: vcf(
+				"BEGIN:VCARD", "VERSION:3.0", "FN:Ada Lovelace", "EMAIL:ada@example.com", "TEL:+1 202 555 0123", "END:VCARD",
+				"BEGIN:VCARD", "VERSION:4.0", "N:Turing;Alan;Mathison;Dr.;", "EMAIL:alan@example.com", "END:VCARD",
+			),
+			want: []contact{
+				{"Ada Lovelace", "ada@example.com", "+12025550123", nil},
+				{"Dr. Alan Mathison Turing", "alan@example.com", "", nil},
+			},
+		},
+		{
+			name: "folded lines",
+			vcf:  vcf("BEGIN:VCARD", "VERSION:3.0", "FN:Ada", " Lovelace", "EMAIL:ada@exa", "\tmple.com", "END:VCARD"),
+			want: []contact{{"AdaLovelace", "ada@example.com", "", nil}},
+		},
+		{
+			name: "fold inside a multibyte rune",
+			vcf:  vcf("BEGIN:VCARD", "VERSION:3.0", "FN:Zo\xc3", " \xab Dubois", "EMAIL:zoe@example.com", "END:VCARD"),
+			want: []contact{{"Zoë Dubois", "zoe@example.com", "", nil}},
+		},
+		{
+			name: "3.0 TYPE=pref",
+			vcf: vcf("BEGIN:VCARD", "VERSION:3.0", "FN:Ada",
+				"EMAIL;TYPE=INTERNET:work@example.com", "EMAIL;TYPE=INTERNET,pref:home@example.com",
+				"TEL;TYPE=WORK:+1 202 555 0100", "item1.TEL;TYPE=CELL;TYPE=PREF:+1 202 555 0123", "END:VCARD"),
+			want: []contact{{"Ada", "home@example.com", "+12025550123", nil}},
+		},
+		{
+			name: "4.0 PREF=",
+			vcf: vcf("BEGIN:VCARD", "VERSION:4.0", "FN:Ada",
+				"EMAIL;PREF=2:work@example.com", "EMAIL;TYPE=home;PREF=1:home@example.com", "EMAIL:other@example.com", "END:VCARD"),
+			want: []contact{{"Ada", "home@example.com", "", nil}},
+		},
+		{
+			name: "tel: URI",
+			vcf:  vcf("BEGIN:VCARD", "VERSION:4.0", "FN:Ada", "EMAIL:ada@example.com", "TEL;VALUE=uri;TYPE=cell:tel:+1-202-555-0123;ext=12", "END:VCARD"),
+			want: []contact{{"Ada", "ada@example.com", "+12025550123", nil}},
+		},
+		{
+			name: "escaped CATEGORIES",
+			vcf: vcf("BEGIN:VCARD", "VERSION:3.0", "FN:Ada", "EMAIL:ada@example.com",
+				`CATEGORIES:R&D,Table\; 4,back\\slash`, "CATEGORIES:vip", "END:VCARD"),
+			want: []contact{{"Ada", "ada@example.com", "", []string{"R&D", "Table; 4", `back\slash`, "vip"}}},
+		},
+		{
+			name:    "unsupported version",
+			vcf:     vcf("BEGIN:VCARD", "VERSION:2.1", "FN:Ada", "EMAIL:ada@example.com", "END:VCARD"),
+			wantErr: `card 1: unsupported vCard version "2.1"`,
+		},
+		{
+			name:    "missing END",
+			vcf:     vcf("BEGIN:VCARD", "VERSION:3.0", "FN:Ada"),
+			wantErr: "missing END:VCARD",
+		},
+		{
+			name:    "not a vCard",
+			vcf:     "name,email\r\n",
+			wantErr: "not a content line",
+		},
+		{
+			name: "duplicate uid",
+			vcf: vcf(
+				"BEGIN:VCARD", "VERSION:3.0", "UID:urn:uuid:"+id.String(), "FN:Ada", "EMAIL:ada@example.com", "END:VCARD",
+				"BEGIN:VCARD", "VERSION:4.0", "UID:urn:uuid:"+id.String(), "FN:Ada King", "EMAIL:ada@example.com", "END:VCARD",
+			),
+			wantErr: "card 2: duplicate uid " + id.String(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contacts, err := ReadVCard(strings.NewReader(test.vcf), nil, "US")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(contacts) != len(test.want) {
+				t.Fatalf("got %d contacts, want %d", len(contacts), len(test.want))
+			}
+			for i, c := range contacts {
+				got := contact{c.Name, c.Email, c.Phone, c.Tags}
+				if want := test.want[i]; got.name != want.name || got.email != want.email || got.phone != want.phone || !slices.Equal(got.tags, want.tags) {
+					t.Errorf("contact %d: got %+v, want %+v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadVCardRequiredFields(t *testing.T) {
+	fields := models.FieldDefinitions{
+		{Key: "company", Label: "Company", Type: models.FieldText, Required: true},
+		{Key: "consent", Label: "Consent", Type: models.FieldBoolean, Required: true},
+	}
+	card := vcf("BEGIN:VCARD", "VERSION:4.0", "FN:Ada", "EMAIL:ada@example.com", "END:VCARD")
+
+	contacts, err := ReadVCard(strings.NewReader(card), fields, "US")
+	if err != nil {
+		t.Fatalf("got error %v, want required custom fields skipped", err)
+	}
+	if len(contacts) != 1 {
+		t.Fatalf("got %d contacts, want 1", len(contacts))
+	}
+	if !fields[0].Required {
+		t.Error("ReadVCard changed the required flag of the caller's fields")
+	}
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	contacts := models.Contacts{
+		{ID: uuid.New(), Name: "Zoë Ærøskøbing-Åkesson Østergård Ångström Dubois de la Fontaine-Sørensen", Email: "zoe@example.com", Phone: "+12025550123", Tags: []string{"R&D", "Table; 4"}},
+		{ID: uuid.New(), Name: "Alan", Email: "alan@example.com"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteVCard(&buf, contacts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\r\n ") {
+		t.Fatalf("got no folded lines in %q", buf.String())
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > vCardLineLength || !utf8.ValidString(line) {
+			t.Errorf("line %q is longer than %d octets or splits a rune", line, vCardLineLength)
+		}
+	}
+
+	got, err := ReadVCard(&buf, nil, "US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(contacts) {
+		t.Fatalf("got %d contacts, want %d", len(got), len(contacts))
+	}
+	for i, c := range got {
+		want := contacts[i]
+		if c.ID != want.ID || c.Name != want.Name || c.Email != want.Email || c.Phone != want.Phone || !slices.Equal(c.Tags, want.Tags) {
+			t.Errorf("contact %d: got %+v, want %+v", i+1, c, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/lloydlobo/go-headcount/internal/xlsx"
+	"github.com/lloydlobo/go-headcount/models"
+)
+
+// WriteXLSX writes contacts as an .xlsx workbook of three sheets:
+//
+//   - Contacts: the columns of WriteCSV, custom fields by label, and when
+//     each contact registered and last checked in.
+//   - Summary: the number and share of contacts in each status.
+//   - Attendance: every status change in time order, with a running count
+//     of contacts checked in.
+func WriteXLSX(w io.Writer, contacts models.Contacts, fields models.FieldDefinitions) error {
+	wb := xlsx.New()
+	writeContactsSheet(wb.AddSheet("Contacts"), contacts, fields)
+	writeSummarySheet(wb.AddSheet("Summary"), contacts)
+	writeAttendanceSheet(wb.AddSheet("Attendance"), contacts)
+	return wb.Write(w)
+}
+
+func writeContactsSheet(sheet *xlsx.Sheet, contacts models.Contacts, fields models.FieldDefinitions) {
+	header := []string{"ID", "Name", "Email", "Phone", "Status", "Tags"}
+	for _, fd := range fields {
+		header = append(header, fd.Label)
+	}
+	header = append(header, "Registered", "Checked in")
+	sheet.AddHeader(header...)
+
+	for i, width := range []float64{38, 24, 30, 18, 12, 24} {
+		sheet.SetColumnWidth(i, width)
+	}
+	sheet.SetColumnWidth(len(header)-2, 18)
+	sheet.SetColumnWidth(len(header)-1, 18)
+
+	for _, c := range contacts {
+		row := []any{c.ID.String(), c.Name, c.Email, c.Phone, c.Status.Label(), strings.Join(c.Tags, ", ")}
+		for _, fd := range fields {
+			row = append(row, c.CustomValue(fd.Key))
+		}
+		registered, _ := c.LastChangeTo(models.StatusRegistered)
+		checkedIn, _ := c.LastChangeTo(models.StatusCheckedIn)
+		row = append(row, registered, checkedIn)
+		sheet.AddRow(row...)
+	}
+}
+
+func writeSummarySheet(sheet *xlsx.Sheet, contacts models.Contacts) {
+	sheet.AddHeader("Status", "Contacts", "Share")
+	sheet.SetColumnWidth(0, 16)
+
+	counts := map[models.Status]int{}
+	for _, c := range contacts {
+		counts[c.Status.Canonical()]++
+	}
+	for _, status := range models.Statuses {
+		sheet.AddRow(status.Label(), counts[status], share(counts[status], len(contacts)))
+	}
+	sheet.AddRow("Total", len(contacts), share(len(contacts), len(contacts)))
+}
+
+func share(n, total int) xlsx.Percent {
+	if total == 0 {
+		return 0
+	}
+	return xlsx.Percent(float64(n) / float64(total))
+}
+
+func writeAttendanceSheet(sheet *xlsx.Sheet, contacts models.Contacts) {
+	sheet.AddHeader("Time", "Name", "Email", "Status", "Checked in")
+	for i, width := range []float64{18, 24, 30, 12, 12} {
+		sheet.SetColumnWidth(i, width)
+	}
+
+	type change struct {
+		contact *models.Contact
+		models.StatusChange
+	}
+	var changes []change
+	for i := range contacts {
+		for _, sc := range contacts[i].History {
+			changes = append(changes, change{&contacts[i], sc})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b change) int { return a.At.Compare(b.At) })
+
+	// Replay each contact's statuses to count who is checked in over time.
+	current := map[*models.Contact]models.Status{}
+	checkedIn := 0
+	for _, ch := range changes {
+		if current[ch.contact].IsEnabled() {
+			checkedIn--
+		}
+		current[ch.contact] = ch.Status.Canonical()
+		if ch.Status.IsEnabled() {
+			checkedIn++
+		}
+		sheet.AddRow(ch.At, ch.contact.Name, ch.contact.Email, ch.Status.Label(), checkedIn)
+	}
+}
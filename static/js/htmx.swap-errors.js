@@ -0,0 +1,26 @@
+/**
+ * Let htmx swap error responses that the server means to be shown:
+ *
+ * - `422 Unprocessable Entity`, which re-renders forms with inline
+ *   validation errors.
+ * - Any 4xx/5xx response retargeted at `#toast-container`, which shows
+ *   an error toast. See handlers.RenderError.
+ * - Any 4xx/5xx page fragment, marked by the `HX-Title` header, e.g. the
+ *   404 page after hx-boost navigation.
+ *
+ * htmx 1.x ignores 4xx/5xx responses by default.
+ *
+ * @see {@link https://htmx.org/docs/#modifying_swapping_behavior_with_events}
+ */
+(function () {
+    document.addEventListener("htmx:beforeSwap", function (evt) {
+        const xhr = evt.detail.xhr;
+        const isToast = xhr.status >= 400 && xhr.getResponseHeader("HX-Retarget") === "#toast-container";
+        const isPage = xhr.status >= 400 && !!xhr.getResponseHeader("HX-Title");
+
+        if (xhr.status === 422 || isToast || isPage) {
+            evt.detail.shouldSwap = true;
+            evt.detail.isError = false;
+        }
+    });
+})();
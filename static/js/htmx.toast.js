@@ -0,0 +1,48 @@
+/**
+ * Show a toast in `#toast-container` for every `showToast` event triggered by
+ * the server via the `HX-Trigger` response header, e.g.
+ *
+ *     HX-Trigger: {"showToast": {"level": "ok", "message": "Added Ada"}}
+ *
+ * The level is a missing.css colorway such as `ok`, `info` or `bad`. Toasts
+ * look like components.ErrorToast and remove themselves after a few seconds.
+ *
+ * @see {@link https://htmx.org/headers/hx-trigger/}
+ */
+(function () {
+    const TOAST_TIMEOUT_MS = 6000;
+
+    document.addEventListener("showToast", function (evt) {
+        const container = document.getElementById("toast-container");
+        if (!container || !evt.detail || !evt.detail.message) {
+            return;
+        }
+
+        const toast = document.createElement("div");
+        toast.className = "box " + (evt.detail.level || "info");
+        toast.setAttribute("role", "status");
+
+        const message = document.createElement("p");
+        message.textContent = evt.detail.message;
+
+        const dismiss = document.createElement("button");
+        dismiss.type = "button";
+        dismiss.className = "iconbutton";
+        dismiss.setAttribute("aria-label", "Dismiss");
+        dismiss.textContent = "×";
+        dismiss.addEventListener("click", function () {
+            toast.remove();
+        });
+
+        const header = document.createElement("header");
+        header.className = "f-row justify-content:space-between align-items:center";
+        header.append(message, dismiss);
+
+        toast.append(header);
+        container.append(toast);
+
+        setTimeout(function () {
+            toast.remove();
+        }, TOAST_TIMEOUT_MS);
+    });
+})();
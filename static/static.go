@@ -0,0 +1,38 @@
+// Package static embeds the frontend assets served under "/static/", and
+// robots.txt, so the binary runs from any working directory and without
+// access to any CDN.
+package static
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/lloydlobo/go-headcount/internal/assets"
+)
+
+// The all: prefix keeps files starting with an underscore, e.g. _hyperscript.
+//
+//go:embed all:css all:hs all:js robots.txt
+var files embed.FS
+
+// FS holds the embedded assets, rooted at the static directory.
+var FS fs.FS = files
+
+// Assets resolves asset paths to content-hashed URLs, see templates.Asset.
+var Assets *assets.Manifest
+
+func init() {
+	var err error
+	if Assets, err = assets.New(FS, "/static/"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// UseDisk serves the assets from dir instead of the embedded copies, for
+// live reload during development. Call it before routes are set up.
+func UseDisk(dir string) {
+	FS = os.DirFS(dir)
+	Assets = assets.Live(FS, "/static/")
+}
@@ -33,7 +33,7 @@ func Navbar(swapOob bool) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(" aria-label=\"Site sections\" class=\"contents\"><ul role=\"list\" style=\"width:-webkit-fill-available;\"><li class=\"logo f-row\" style=\"flex:1;\"><a href=\"/\" aria-label=\"Home\"><span>head<b>count</b></span></a><hr class=\"vh\" aria-orientation=\"vertical\"></li><li><a href=\"/about\">About</a></li><li><a href=\"https://github.com/lloydlobo/go-headcount\">GitHub</a></li><!-- <li><a href=\"/\"><img alt=\"\"/></a></li> --></ul></nav></header>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(" aria-label=\"Site sections\" class=\"contents\" hx-boost=\"true\" hx-target=\"#mainContainer\" hx-swap=\"innerHTML\"><ul role=\"list\" style=\"width:-webkit-fill-available;\"><li class=\"logo f-row\" style=\"flex:1;\"><a href=\"/\" aria-label=\"Home\"><span>head<b>count</b></span></a><hr class=\"vh\" aria-orientation=\"vertical\"></li><li><a href=\"/stats\">Stats</a></li><li><a href=\"/contacts/duplicates\">Duplicates</a></li><li><a href=\"/tags\">Tags</a></li><li><a href=\"/admin/fields\">Fields</a></li><li><a href=\"/admin/backups\">Backups</a></li><li><a href=\"/about\">About</a></li><li><a href=\"https://github.com/lloydlobo/go-headcount\">GitHub</a></li><!-- <li><a href=\"/\"><img alt=\"\"/></a></li> --></ul></nav></header>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
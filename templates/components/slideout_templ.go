@@ -80,7 +80,23 @@ func Slideout(contents templ.Component, triggerText string, isOpen bool) templ.C
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</aside></div><style type=\"text/css\">\n        aside.slideout-aside {\n            position: fixed; \n            top: 0; \n            right: 0; \n            bottom: 0; \n            min-width: 300px; \n            margin-block: 0;\n            z-index: 50;\n        }\n    </style><style type=\"text/css\">\n        .transition {\n            transition: ease-out 300ms;\n        }\n        .ease-out {\n            transition-timing-function: ease-out;\n        }\n        .duration-300 {\n            transition-duration: 300ms;\n        }\n        .translate-x-full {\n            transform: translateX(100%);\n        }\n        .translate-x-0 {\n            transform: translateX(0);\n        }\n        .ease-in {\n            transition-timing-function: ease-in;\n        }\n    </style>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</aside></div><style type=\"text/css\" nonce=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templates.CSPNonce(ctx)))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\">\n        aside.slideout-aside {\n            position: fixed; \n            top: 0; \n            right: 0; \n            bottom: 0; \n            min-width: 300px; \n            margin-block: 0;\n            z-index: 50;\n        }\n    </style><style type=\"text/css\" nonce=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templates.CSPNonce(ctx)))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\">\n        .transition {\n            transition: ease-out 300ms;\n        }\n        .ease-out {\n            transition-timing-function: ease-out;\n        }\n        .duration-300 {\n            transition-duration: 300ms;\n        }\n        .translate-x-full {\n            transform: translateX(100%);\n        }\n        .translate-x-0 {\n            transform: translateX(0);\n        }\n        .ease-in {\n            transition-timing-function: ease-in;\n        }\n    </style>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -1,16 +1,78 @@
 package templates
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/lloydlobo/go-headcount/internal"
+	"github.com/lloydlobo/go-headcount/static"
+)
+
+type contextKey string
+
+const (
+	pageTitleKey contextKey = "pageTitle"
+	fragmentKey  contextKey = "fragment"
+)
+
+// DefaultPageTitle is the page title when none is set with WithPageTitle.
+const DefaultPageTitle = "Headcount | Home"
+
+// WithPageTitle returns a copy of ctx rendering pages titled title.
+func WithPageTitle(ctx context.Context, title string) context.Context {
+	return context.WithValue(ctx, pageTitleKey, title)
+}
 
 // Used by templates/components/title_templ.go
 func GetPageTitle(ctx context.Context) string {
-	title, ok := ctx.Value("pageTitle").(string)
+	title, ok := ctx.Value(pageTitleKey).(string)
 	if !ok {
-		return "Headcount | Home"
+		return DefaultPageTitle
 	}
 	return title
 }
 
+// WithFragment returns a copy of ctx rendering pages as the body fragment
+// swapped in by htmx, instead of the full layout. See pages.Page.
+func WithFragment(ctx context.Context, fragment bool) context.Context {
+	return context.WithValue(ctx, fragmentKey, fragment)
+}
+
+// IsFragment reports whether pages render as a body fragment.
+func IsFragment(ctx context.Context) bool {
+	fragment, _ := ctx.Value(fragmentKey).(bool)
+	return fragment
+}
+
+// CSRFToken returns the token of internal.CSRF, rendered into pages.Base.
+func CSRFToken(ctx context.Context) string { return internal.CSRFToken(ctx) }
+
+// CSPNonce returns the nonce of internal.SecureHeaders, required on every
+// script and style element.
+func CSPNonce(ctx context.Context) string { return internal.CSPNonce(ctx) }
+
+// HtmxConfig returns the htmx-config meta value. htmx must neither inject
+// its indicator styles, which live in static/css/style.css, nor run swapped
+// in scripts without the nonce.
+func HtmxConfig(ctx context.Context) string {
+	b, _ := json.Marshal(map[string]any{
+		"includeIndicatorStyles": false,
+		"inlineScriptNonce":      internal.CSPNonce(ctx),
+	})
+	return string(b)
+}
+
+// CSRFHeaders returns the hx-headers value making htmx send the token of
+// internal.CSRF with every request.
+func CSRFHeaders(ctx context.Context) string {
+	b, _ := json.Marshal(map[string]string{internal.CSRFHeaderName: internal.CSRFToken(ctx)})
+	return string(b)
+}
+
+// Asset returns the content-hashed URL of the static file at name, e.g.
+// "js/htmx.min.js".
+func Asset(name string) string { return static.Assets.Path(name) }
+
 func BoolToStrJS(b bool) string {
 	if b {
 		return "true"